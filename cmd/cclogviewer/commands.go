@@ -0,0 +1,143 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/brads3290/cclogviewer/internal/constants"
+	"github.com/brads3290/cclogviewer/internal/drift"
+	"github.com/brads3290/cclogviewer/internal/patch"
+	"github.com/brads3290/cclogviewer/internal/processor/tools/diff"
+	"github.com/brads3290/cclogviewer/internal/replay"
+)
+
+// errMissingInput is returned by commands run without an input file
+var errMissingInput = errors.New("no input file, provide one using -input flag")
+
+// commands are run as "cclogviewer <command> [flags]"
+var commands = map[string]func(args []string) error{
+	"export-patch": runExportPatch,
+	"replay":       runReplay,
+	"drift":        runDrift,
+}
+
+// runExportPatch writes the file changes of a session as a patch for git apply
+func runExportPatch(args []string) error {
+	var inputFile, outputFile, agentFiles string
+	flags := flag.NewFlagSet("export-patch", flag.ExitOnError)
+	flags.StringVar(&inputFile, "input", "", "Input JSONL file path")
+	flags.StringVar(&outputFile, "output", "", "Output patch file path (default: standard output)")
+	flags.StringVar(&agentFiles, "agents", "", "Comma-separated subagent JSONL files (default: agent-*.jsonl files of the session)")
+	flags.Parse(args)
+
+	if inputFile == "" {
+		return errMissingInput
+	}
+
+	session, err := loadSession(inputFile, agentFiles)
+	if err != nil {
+		return err
+	}
+
+	var out io.Writer = os.Stdout
+	if outputFile != "" {
+		file, err := os.Create(outputFile)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		out = file
+	}
+
+	skipped, err := patch.Write(out, session.Files)
+	if err != nil {
+		return fmt.Errorf("writing patch: %w", err)
+	}
+	for _, file := range skipped {
+		log.Printf("Warning: %s left out of the patch: %s", file.Path, file.Reason)
+	}
+
+	return nil
+}
+
+// runReplay applies the file changes of a session to a directory
+func runReplay(args []string) error {
+	var inputFile, targetDir, agentFiles string
+	var dryRun bool
+	flags := flag.NewFlagSet("replay", flag.ExitOnError)
+	flags.StringVar(&inputFile, "input", "", "Input JSONL file path")
+	flags.StringVar(&targetDir, "dir", ".", "Directory to apply the changes to")
+	flags.BoolVar(&dryRun, "dry-run", false, "Print what would change without writing any file")
+	flags.StringVar(&agentFiles, "agents", "", "Comma-separated subagent JSONL files (default: agent-*.jsonl files of the session)")
+	flags.Parse(args)
+
+	if inputFile == "" {
+		return errMissingInput
+	}
+
+	session, err := loadSession(inputFile, agentFiles)
+	if err != nil {
+		return err
+	}
+
+	result, err := replay.Replay(session.Files, targetDir, dryRun)
+	if err != nil {
+		return fmt.Errorf("replaying changes: %w", err)
+	}
+
+	for _, step := range result.Steps {
+		if step.Conflict != "" {
+			fmt.Printf("conflict  %-9s %s: %s\n", step.Tool, step.Path, step.Conflict)
+		} else {
+			fmt.Printf("applied   %-9s %s\n", step.Tool, step.Path)
+		}
+	}
+
+	if dryRun && len(result.Changes) > 0 {
+		fmt.Println()
+		for _, change := range result.Changes {
+			hunks := diff.ComputeFilePatch(change.Before, change.After, constants.DiffContextLines)
+			if err := patch.WriteFile(os.Stdout, change.Path, !change.Existed, hunks); err != nil {
+				return err
+			}
+		}
+	}
+
+	if conflicts := result.Conflicts(); conflicts > 0 {
+		return fmt.Errorf("%d of %d changes could not be applied", conflicts, len(result.Steps))
+	}
+	return nil
+}
+
+// runDrift reports whether the files a session changed still hold what it left
+func runDrift(args []string) error {
+	var inputFile, projectDir, agentFiles string
+	flags := flag.NewFlagSet("drift", flag.ExitOnError)
+	flags.StringVar(&inputFile, "input", "", "Input JSONL file path")
+	flags.StringVar(&projectDir, "dir", ".", "Project directory of the session")
+	flags.StringVar(&agentFiles, "agents", "", "Comma-separated subagent JSONL files (default: agent-*.jsonl files of the session)")
+	flags.Parse(args)
+
+	if inputFile == "" {
+		return errMissingInput
+	}
+
+	session, err := loadSession(inputFile, agentFiles)
+	if err != nil {
+		return err
+	}
+
+	reports, err := drift.Check(session.Files, projectDir)
+	if err != nil {
+		return fmt.Errorf("checking files: %w", err)
+	}
+
+	for _, report := range reports {
+		fmt.Printf("%-14s %s\n", report.Status, report.Path)
+	}
+	return nil
+}
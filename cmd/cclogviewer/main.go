@@ -7,8 +7,6 @@ import (
 	"github.com/brads3290/cclogviewer/internal/constants"
 	debugpkg "github.com/brads3290/cclogviewer/internal/debug"
 	"github.com/brads3290/cclogviewer/internal/models"
-	"github.com/brads3290/cclogviewer/internal/parser"
-	"github.com/brads3290/cclogviewer/internal/processor"
 	"github.com/brads3290/cclogviewer/internal/renderer"
 	"log"
 	"os"
@@ -26,14 +24,28 @@ var (
 )
 
 func main() {
-	var inputFile, outputFile string
-	var openBrowser, showVersion, showContextSize bool
+	// Subcommands take the place of the first argument
+	if len(os.Args) > 1 {
+		if command, ok := commands[os.Args[1]]; ok {
+			if err := command(os.Args[2:]); err != nil {
+				log.Fatalf("Error running %s: %v", os.Args[1], err)
+			}
+			return
+		}
+	}
+
+	var inputFile, outputFile, agentFiles, formattersDir string
+	var openBrowser, showVersion, showContextSize, hideThinking, rawMarkdown bool
 	flag.StringVar(&inputFile, "input", "", "Input JSONL file path")
 	flag.StringVar(&outputFile, "output", "", "Output HTML file path (optional)")
 	flag.BoolVar(&openBrowser, "open", false, "Open the generated HTML file in browser")
 	flag.BoolVar(&debugpkg.Enabled, "debug", false, "Enable debug logging")
 	flag.BoolVar(&showVersion, "version", false, "Show version information")
 	flag.BoolVar(&showContextSize, "contextsize", false, "Print the conversation size from the last assistant message")
+	flag.BoolVar(&hideThinking, "hide-thinking", false, "Leave extended thinking blocks out of the generated HTML")
+	flag.BoolVar(&rawMarkdown, "raw-markdown", false, "Show message text as written instead of rendering its Markdown")
+	flag.StringVar(&agentFiles, "agents", "", "Comma-separated subagent JSONL files (default: agent-*.jsonl files of the session)")
+	flag.StringVar(&formattersDir, "formatters", "", "Directory of user-defined tool formatters (default: cclogviewer/formatters in the user config directory)")
 	flag.Parse()
 
 	if showVersion {
@@ -73,12 +85,15 @@ func main() {
 		autoOpen = true
 	}
 
-	entries, err := parser.ReadJSONLFile(inputFile)
-	if err != nil {
-		log.Fatalf("Error reading file: %v", err)
+	// Formatters must be registered before tool calls are formatted while loading
+	if err := loadFormatters(formattersDir); err != nil {
+		log.Fatalf("Error loading formatters: %v", err)
 	}
 
-	processed := processor.ProcessEntries(entries)
+	session, err := loadSession(inputFile, agentFiles)
+	if err != nil {
+		log.Fatalf("Error loading session: %v", err)
+	}
 
 	// If -contextsize flag is set, print the conversation size and exit
 	if showContextSize {
@@ -106,7 +121,7 @@ func main() {
 			}
 		}
 		
-		findLastAssistant(processed)
+		findLastAssistant(session.Entries)
 		
 		if foundAssistant {
 			fmt.Println(lastAssistantTokens)
@@ -116,7 +131,11 @@ func main() {
 		os.Exit(0)
 	}
 
-	err = renderer.GenerateHTML(processed, outputFile, debugpkg.Enabled)
+	err = renderer.GenerateSessionHTML(session, outputFile, renderer.Options{
+		Debug:        debugpkg.Enabled,
+		HideThinking: hideThinking,
+		RawMarkdown:  rawMarkdown,
+	})
 	if err != nil {
 		log.Fatalf("Error generating HTML: %v", err)
 	}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/brads3290/cclogviewer/internal/constants"
+	"github.com/brads3290/cclogviewer/internal/models"
+	"github.com/brads3290/cclogviewer/internal/parser"
+	"github.com/brads3290/cclogviewer/internal/processor"
+)
+
+// loadSession reads and processes a session log together with its subagent transcripts.
+// agentFiles is a comma-separated list of transcripts; if empty they are found automatically.
+func loadSession(inputFile, agentFiles string) (*models.Session, error) {
+	// Stream entries into the processor so the raw log is never held in memory at once
+	streamProcessor := processor.NewStreamProcessor()
+	var sessionID string
+	err := parser.StreamJSONLFile(inputFile, func(record *parser.Record) error {
+		if sessionID == "" {
+			sessionID = record.Entry.SessionID
+		}
+		streamProcessor.Add(record.Entry)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("reading file: %w", err)
+	}
+
+	// Newer Claude Code versions write Task subagent conversations to their own files
+	var subagentFiles []string
+	if agentFiles != "" {
+		subagentFiles = splitFileList(agentFiles)
+	} else {
+		subagentFiles, err = parser.FindSubagentFiles(inputFile, sessionID)
+		if err != nil {
+			return nil, fmt.Errorf("finding subagent files: %w", err)
+		}
+	}
+	for _, agentFile := range subagentFiles {
+		err := parser.StreamSubagentFile(agentFile, func(record *parser.Record) error {
+			streamProcessor.Add(record.Entry)
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("reading subagent file: %w", err)
+		}
+	}
+
+	return streamProcessor.FinishSession(), nil
+}
+
+// splitFileList splits a comma-separated list of files, ignoring spaces around the names
+// and empty items
+func splitFileList(list string) []string {
+	var files []string
+	for _, file := range strings.Split(list, ",") {
+		if file = strings.TrimSpace(file); file != "" {
+			files = append(files, file)
+		}
+	}
+	return files
+}
+
+// loadFormatters registers the user-defined tool formatters of a directory. If dir is empty,
+// the formatters directory of the user's config directory is used when it exists.
+func loadFormatters(dir string) error {
+	if dir == "" {
+		configDir, err := os.UserConfigDir()
+		if err != nil {
+			return nil
+		}
+		dir = filepath.Join(configDir, constants.FormatterConfigDirectory)
+		if _, err := os.Stat(dir); err != nil {
+			return nil
+		}
+	}
+
+	return processor.LoadToolFormatters(dir)
+}
@@ -9,6 +9,9 @@ const (
 	
 	// HTMLBuilderInitialCapacity is the initial capacity for HTML string builders
 	HTMLBuilderInitialCapacity = 100
+	
+	// MaxInlineImageSize is the largest decoded image embedded in the HTML (5MB)
+	MaxInlineImageSize = 5 * 1024 * 1024
 )
 
 // Time durations
@@ -33,6 +36,10 @@ const (
 	
 	// ThousandsSeparatorInterval is the digit grouping for number formatting
 	ThousandsSeparatorInterval = 3
+	
+	// JSONTreeOpenDepth is the number of levels of a JSON tree shown expanded; deeper
+	// objects and arrays start collapsed
+	JSONTreeOpenDepth = 2
 )
 
 // Entry types
@@ -42,31 +49,60 @@ const (
 	EntryTypeToolCall   = "tool_call"
 	EntryTypeToolResult = "tool_result"
 	EntryTypeSummary    = "summary"
+	EntryTypeSystem     = "system"
+	EntryTypeFileHistorySnapshot = "file-history-snapshot"
 	
 	// Message roles
 	RoleUser      = "user"
 	RoleAssistant = "assistant"
 	RoleSystem    = "system"
+
+	// System entry levels
+	SystemLevelError = "error"
 	
 	// Content types
-	ContentTypeText       = "text"
-	ContentTypeToolUse    = "tool_use"
-	ContentTypeToolResult = "tool_result"
+	ContentTypeText             = "text"
+	ContentTypeToolUse          = "tool_use"
+	ContentTypeToolResult       = "tool_result"
+	ContentTypeThinking         = "thinking"
+	ContentTypeRedactedThinking = "redacted_thinking"
+	ContentTypeImage            = "image"
+	
+	// Image source types
+	ImageSourceBase64 = "base64"
 )
 
+// InlineImageMediaTypes lists the image types that may be embedded in the HTML
+var InlineImageMediaTypes = map[string]bool{
+	"image/png":  true,
+	"image/jpeg": true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
 // Tool names
 const (
 	// TaskToolName is the name of the Task tool that creates sidechains
 	TaskToolName = "Task"
 	
 	// Common tool names
-	ToolNameBash      = "Bash"
-	ToolNameWebSearch = "WebSearch"
-	ToolNameRead      = "Read"
-	ToolNameEdit      = "Edit"
-	ToolNameMultiEdit = "MultiEdit"
-	ToolNameWrite     = "Write"
-	ToolNameTodoWrite = "TodoWrite"
+	ToolNameBash         = "Bash"
+	ToolNameWebSearch    = "WebSearch"
+	ToolNameWebFetch     = "WebFetch"
+	ToolNameRead         = "Read"
+	ToolNameEdit         = "Edit"
+	ToolNameMultiEdit    = "MultiEdit"
+	ToolNameWrite        = "Write"
+	ToolNameTodoWrite    = "TodoWrite"
+	ToolNameGrep         = "Grep"
+	ToolNameGlob         = "Glob"
+	ToolNameLS           = "LS"
+	ToolNameNotebookEdit = "NotebookEdit"
+	
+	// MCPToolPrefix starts the names of MCP tools, which are named mcp__<server>__<tool>
+	MCPToolPrefix = "mcp__"
+	// MCPToolSeparator separates the server of an MCP tool from the tool name
+	MCPToolSeparator = "__"
 )
 
 // Version information
@@ -95,6 +131,19 @@ const (
 	// HTMLFileExtension is the file extension for HTML files
 	HTMLFileExtension = ".html"
 	
+	// JSONLFileExtension is the file extension for log files
+	JSONLFileExtension = ".jsonl"
+	
+	// SubagentFilePrefix is the file name prefix of subagent transcripts
+	SubagentFilePrefix = "agent-"
+	
+	// SubagentDirectoryName is the directory, inside a session directory, holding subagent transcripts
+	SubagentDirectoryName = "subagents"
+	
+	// FormatterConfigDirectory is the directory, inside the user's config directory, holding
+	// user-defined tool formatters
+	FormatterConfigDirectory = "cclogviewer/formatters"
+	
 	// TemplateDirectoryPrefix is the prefix for template directories
 	TemplateDirectoryPrefix = "templates/"
 	
@@ -151,11 +200,62 @@ const (
 	PerfectMatchScore = 2
 )
 
+// Sidechain link methods and confidence levels
+const (
+	// Link methods, from most to least reliable
+	LinkMethodAgentID    = "agent-id"
+	LinkMethodParentUUID = "parent-uuid"
+	LinkMethodTimestamp  = "timestamp"
+	LinkMethodText       = "text"
+
+	// Confidence levels
+	LinkConfidenceHigh   = "high"
+	LinkConfidenceMedium = "medium"
+	LinkConfidenceLow    = "low"
+
+	// ToolUseResultAgentIDKey is the toolUseResult field holding a Task's subagent ID
+	ToolUseResultAgentIDKey = "agentId"
+)
+
 // Processing configuration
 const (
 	// LineNumberStartIndex is the starting line number for diffs
 	LineNumberStartIndex = 1
 	
+	// DiffContextLines is the number of unchanged lines shown around each change in a diff
+	DiffContextLines = 3
+	
+	// DiffFoldMinLines is the minimum number of unchanged lines worth folding away in a diff
+	DiffFoldMinLines = 4
+	
+	// ReadDefaultLineLimit is the number of lines the Read tool returns when no limit is given
+	ReadDefaultLineLimit = 2000
+	
+	// WriteResultTypeCreate is the type of a Write tool result that created a new file
+	WriteResultTypeCreate = "create"
+	
+	// GrepOutputModeContent, GrepOutputModeFiles and GrepOutputModeCount are the output
+	// modes of the Grep tool; GrepOutputModeFiles is the default
+	GrepOutputModeContent = "content"
+	GrepOutputModeFiles   = "files_with_matches"
+	GrepOutputModeCount   = "count"
+	
+	// NotebookEditModeReplace, NotebookEditModeInsert and NotebookEditModeDelete are the edit
+	// modes of the NotebookEdit tool; NotebookEditModeReplace is the default
+	NotebookEditModeReplace = "replace"
+	NotebookEditModeInsert  = "insert"
+	NotebookEditModeDelete  = "delete"
+	
+	// NotebookExtension is the file extension of Jupyter notebooks
+	NotebookExtension = ".ipynb"
+	
+	// NotebookDefaultLanguage is the language of notebook code cells when the notebook doesn't name one
+	NotebookDefaultLanguage = "python"
+	
+	// IntraLineMinSimilarity is the share of text a changed line pair must have in common
+	// to highlight the changed words instead of the whole lines
+	IntraLineMinSimilarity = 0.5
+	
 	// RootConversationDepth is the depth of root-level entries
 	RootConversationDepth = 1
 )
@@ -0,0 +1,2 @@
+// Package drift compares the files a session changed with their content on disk.
+package drift
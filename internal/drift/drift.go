@@ -0,0 +1,111 @@
+package drift
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/brads3290/cclogviewer/internal/constants"
+	"github.com/brads3290/cclogviewer/internal/models"
+)
+
+// Status is the state of a changed file on disk compared to what the session left.
+type Status string
+
+const (
+	// StatusCurrent means the file holds the content the session left
+	StatusCurrent Status = "current"
+	// StatusEditsPresent means the session's final content is unknown, but the text of its
+	// last edit is still in the file
+	StatusEditsPresent Status = "edits-present"
+	// StatusModified means the file was changed after the session
+	StatusModified Status = "modified"
+	// StatusReverted means the file is back to its content from before the session
+	StatusReverted Status = "reverted"
+	// StatusDeleted means the file no longer exists
+	StatusDeleted Status = "deleted"
+	// StatusUnknown means neither the session's final content nor its last edit can be checked
+	StatusUnknown Status = "unknown"
+)
+
+// Report is the state of a single file the session wrote or edited.
+type Report struct {
+	Path   string // Path relative to the project directory, or absolute if it's outside it
+	Status Status
+}
+
+// Check reports, for every file the session wrote or edited, whether the file in dir still
+// holds the content the session left. Files outside the working directory of the session
+// are checked at their absolute path.
+func Check(files []*models.FileHistory, dir string) ([]Report, error) {
+	var reports []Report
+
+	for _, file := range files {
+		if !file.Changed() {
+			continue
+		}
+
+		path, diskPath := file.Path, file.Path
+		if rel, err := file.RelativePath(); err == nil {
+			path, diskPath = rel, filepath.Join(dir, filepath.FromSlash(rel))
+		}
+
+		content, err := os.ReadFile(diskPath)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return reports, err
+		}
+		exists := err == nil
+
+		reports = append(reports, Report{Path: path, Status: status(file, string(content), exists)})
+	}
+
+	return reports, nil
+}
+
+// status compares the content of a file on disk with its history
+func status(file *models.FileHistory, content string, exists bool) Status {
+	if !exists {
+		if file.Created {
+			return StatusReverted
+		}
+		return StatusDeleted
+	}
+
+	final := file.Final()
+	switch {
+	case final.ContentKnown && content == final.Content:
+		return StatusCurrent
+	case file.InitialKnown && !file.Created && content == file.InitialContent:
+		return StatusReverted
+	case final.ContentKnown:
+		return StatusModified
+	}
+
+	// Without the final content, look for the text of the last edit
+	edits := lastChange(file).Edits
+	if len(edits) == 0 {
+		return StatusUnknown
+	}
+	lastEdit := edits[len(edits)-1]
+	switch {
+	case lastEdit.NewString == "":
+		return StatusUnknown
+	case strings.Contains(content, lastEdit.NewString):
+		return StatusEditsPresent
+	default:
+		return StatusModified
+	}
+}
+
+// lastChange returns the last successful call that changed a file
+func lastChange(file *models.FileHistory) *models.FileOperation {
+	for i := len(file.Operations) - 1; i >= 0; i-- {
+		op := &file.Operations[i]
+		if op.Succeeded && op.Tool != constants.ToolNameRead {
+			return op
+		}
+	}
+	return file.Final()
+}
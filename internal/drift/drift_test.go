@@ -0,0 +1,83 @@
+package drift
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/brads3290/cclogviewer/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writtenFile returns the history of a file written once with known content
+func writtenFile(dir, name, initial, final string, created bool) *models.FileHistory {
+	return &models.FileHistory{
+		Path:           filepath.Join(dir, name),
+		InitialContent: initial,
+		InitialKnown:   true,
+		Created:        created,
+		Operations: []models.FileOperation{
+			{Tool: "Write", Succeeded: true, CWD: dir, Written: final, Content: final, ContentKnown: true},
+		},
+	}
+}
+
+// editedFile returns the history of a file edited once with unknown content
+func editedFile(dir, name, newString string) *models.FileHistory {
+	return &models.FileHistory{
+		Path: filepath.Join(dir, name),
+		Operations: []models.FileOperation{
+			{Tool: "Edit", Succeeded: true, CWD: dir, Edits: []models.FileEdit{{OldString: "old", NewString: newString}}},
+		},
+	}
+}
+
+func TestCheck(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"current.go":  "final\n",
+		"modified.go": "later\n",
+		"reverted.go": "before\n",
+		"present.go":  "a\nnew text\nb\n",
+		"missing.go":  "a\n",
+		"removed.go":  "a\n",
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+
+	files := []*models.FileHistory{
+		writtenFile(dir, "current.go", "", "final\n", true),
+		writtenFile(dir, "modified.go", "before\n", "final\n", false),
+		writtenFile(dir, "reverted.go", "before\n", "final\n", false),
+		writtenFile(dir, "deleted.go", "before\n", "final\n", false),
+		writtenFile(dir, "uncreated.go", "", "final\n", true),
+		editedFile(dir, "present.go", "new text"),
+		editedFile(dir, "missing.go", "new text"),
+		editedFile(dir, "removed.go", ""),
+		{Path: filepath.Join(dir, "read.go"), Operations: []models.FileOperation{{Tool: "Read", Succeeded: true}}},
+	}
+
+	reports, err := Check(files, dir)
+	require.NoError(t, err)
+
+	assert.Equal(t, []Report{
+		{Path: "current.go", Status: StatusCurrent},
+		{Path: "modified.go", Status: StatusModified},
+		{Path: "reverted.go", Status: StatusReverted},
+		{Path: "deleted.go", Status: StatusDeleted},
+		{Path: "uncreated.go", Status: StatusReverted},
+		{Path: "present.go", Status: StatusEditsPresent},
+		{Path: "missing.go", Status: StatusModified},
+		{Path: "removed.go", Status: StatusUnknown},
+	}, reports)
+}
+
+func TestCheck_ProjectMoved(t *testing.T) {
+	oldDir, newDir := t.TempDir(), t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(newDir, "main.go"), []byte("final\n"), 0644))
+
+	reports, err := Check([]*models.FileHistory{writtenFile(oldDir, "main.go", "", "final\n", true)}, newDir)
+	require.NoError(t, err)
+	assert.Equal(t, []Report{{Path: "main.go", Status: StatusCurrent}}, reports)
+}
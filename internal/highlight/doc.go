@@ -0,0 +1,2 @@
+// Package highlight provides syntax highlighting of source code by file type.
+package highlight
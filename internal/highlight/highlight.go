@@ -0,0 +1,147 @@
+package highlight
+
+import (
+	"html"
+	"strings"
+)
+
+// CSS classes of highlighted tokens, styled in themes.css
+const (
+	ClassKeyword  = "hl-keyword"
+	ClassType     = "hl-type"
+	ClassLiteral  = "hl-literal"
+	ClassBuiltin  = "hl-builtin"
+	ClassFunction = "hl-function"
+	ClassString   = "hl-string"
+	ClassNumber   = "hl-number"
+	ClassComment  = "hl-comment"
+	ClassKey      = "hl-key"
+	ClassVariable = "hl-variable"
+	ClassHeading  = "hl-heading"
+	ClassCode     = "hl-code"
+	ClassLink     = "hl-link"
+	ClassEmphasis = "hl-emphasis"
+)
+
+// Token is a piece of a line together with the syntax class it belongs to.
+type Token struct {
+	Class string // CSS class, empty for plain text
+	Text  string
+}
+
+// Highlighter highlights the lines of a file one after another. It carries state such as
+// an open block comment from one line to the next, so lines must be passed in order.
+// A nil Highlighter leaves every line plain.
+type Highlighter struct {
+	lang  *language
+	state lexState
+	fence *Highlighter // Highlighter of an open fenced code block in Markdown
+}
+
+// lexState is a construct spanning lines that is still open at the end of a line
+type lexState struct {
+	closer  string // Delimiter that ends the construct, empty if none is open
+	class   string
+	escapes bool // Whether a backslash escapes the next character
+}
+
+// ForFile returns a highlighter for the language of a file, picked by its extension
+// or name, or nil if the language isn't known.
+func ForFile(path string) *Highlighter {
+	return newHighlighter(languageForFile(path))
+}
+
+// ForLanguage returns a highlighter for a language name such as "go" or "python", as used
+// by Markdown code fences, or nil if the language isn't known.
+func ForLanguage(name string) *Highlighter {
+	return newHighlighter(languageNames[strings.ToLower(strings.TrimSpace(name))])
+}
+
+// newHighlighter returns a highlighter for a language, nil if the language is nil
+func newHighlighter(lang *language) *Highlighter {
+	if lang == nil {
+		return nil
+	}
+	return &Highlighter{lang: lang}
+}
+
+// Line splits the next line of the file into tokens
+func (h *Highlighter) Line(line string) []Token {
+	if h == nil {
+		return []Token{{Text: line}}
+	}
+
+	var tokens tokenList
+	if h.lang.lexLine != nil {
+		h.lang.lexLine(h, line, &tokens)
+	} else {
+		h.lexCode(line, &tokens)
+	}
+	return tokens
+}
+
+// LineHTML highlights the next line of the file as escaped HTML
+func (h *Highlighter) LineHTML(line string) string {
+	return HTML(h.Line(line))
+}
+
+// HTML renders tokens as escaped HTML with a span around each highlighted token
+func HTML(tokens []Token) string {
+	var result strings.Builder
+	for _, token := range tokens {
+		WriteToken(&result, token)
+	}
+	return result.String()
+}
+
+// WriteToken writes a single token as escaped HTML
+func WriteToken(result *strings.Builder, token Token) {
+	if token.Class == "" {
+		result.WriteString(html.EscapeString(token.Text))
+		return
+	}
+	result.WriteString(`<span class="`)
+	result.WriteString(token.Class)
+	result.WriteString(`">`)
+	result.WriteString(html.EscapeString(token.Text))
+	result.WriteString(`</span>`)
+}
+
+// Code highlights the whole content of a file as escaped HTML, keeping its newlines.
+// Files of unknown languages are only escaped.
+func Code(path, code string) string {
+	return codeHTML(ForFile(path), code)
+}
+
+// LanguageCode is like Code for code in a language named like "python"
+func LanguageCode(language, code string) string {
+	return codeHTML(ForLanguage(language), code)
+}
+
+// codeHTML highlights lines of code with a highlighter, only escaping them if it is nil
+func codeHTML(h *Highlighter, code string) string {
+	if h == nil {
+		return html.EscapeString(code)
+	}
+
+	lines := strings.Split(code, "\n")
+	for i, line := range lines {
+		lines[i] = h.LineHTML(line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// tokenList collects the tokens of a line, merging neighbours of the same class
+type tokenList []Token
+
+// add appends text with a class to the list
+func (l *tokenList) add(class, text string) {
+	if text == "" {
+		return
+	}
+	if n := len(*l); n > 0 && (*l)[n-1].Class == class {
+		(*l)[n-1].Text += text
+		return
+	}
+	*l = append(*l, Token{Class: class, Text: text})
+}
@@ -0,0 +1,171 @@
+package highlight
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// classesOf returns the text of each highlighted token of a line by class
+func classesOf(tokens []Token) map[string][]string {
+	classes := make(map[string][]string)
+	for _, token := range tokens {
+		if token.Class != "" {
+			classes[token.Class] = append(classes[token.Class], token.Text)
+		}
+	}
+	return classes
+}
+
+func TestLine(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		line     string
+		expected map[string][]string
+	}{
+		{
+			name: "go",
+			path: "main.go",
+			line: `func main() { fmt.Println("hi", 42, nil) } // done`,
+			expected: map[string][]string{
+				ClassKeyword:  {"func"},
+				ClassFunction: {"main", "Println"},
+				ClassString:   {`"hi"`},
+				ClassNumber:   {"42"},
+				ClassLiteral:  {"nil"},
+				ClassComment:  {"// done"},
+			},
+		},
+		{
+			name: "typescript",
+			path: "src/App.tsx",
+			line: `const $el: string = 'x' as unknown;`,
+			expected: map[string][]string{
+				ClassKeyword: {"const", "as"},
+				ClassType:    {"string", "unknown"},
+				ClassString:  {"'x'"},
+			},
+		},
+		{
+			name: "python",
+			path: "tool.py",
+			line: `def run(self): return None  # todo`,
+			expected: map[string][]string{
+				ClassKeyword:  {"def", "return"},
+				ClassFunction: {"run"},
+				ClassBuiltin:  {"self"},
+				ClassLiteral:  {"None"},
+				ClassComment:  {"# todo"},
+			},
+		},
+		{
+			name: "json",
+			path: "package.json",
+			line: `  "name": "app", "private": true, "version": 2`,
+			expected: map[string][]string{
+				ClassKey:     {`"name"`, `"private"`, `"version"`},
+				ClassString:  {`"app"`},
+				ClassLiteral: {"true"},
+				ClassNumber:  {"2"},
+			},
+		},
+		{
+			name: "yaml",
+			path: "ci.yml",
+			line: `  - run-on: true # comment`,
+			expected: map[string][]string{
+				ClassKey:     {"run-on"},
+				ClassLiteral: {"true"},
+				ClassComment: {"# comment"},
+			},
+		},
+		{
+			name: "shell",
+			path: "build.sh",
+			line: `if [ -n "$X" ]; then echo ${HOME}/bin-dir $1; fi # note`,
+			expected: map[string][]string{
+				ClassKeyword:  {"if", "then", "fi"},
+				ClassString:   {`"$X"`},
+				ClassBuiltin:  {"echo"},
+				ClassVariable: {"${HOME}", "$1"},
+				ClassComment:  {"# note"},
+			},
+		},
+		{
+			name: "shell hash inside a word",
+			path: "run.sh",
+			line: `echo a#b`,
+			expected: map[string][]string{
+				ClassBuiltin: {"echo"},
+			},
+		},
+		{
+			name: "markdown list",
+			path: "README.md",
+			line: "- Run `make` and see **docs** at [site](https://example.com)",
+			expected: map[string][]string{
+				ClassKeyword:  {"-"},
+				ClassCode:     {"`make`"},
+				ClassEmphasis: {"**docs**"},
+				ClassLink:     {"[site](https://example.com)"},
+			},
+		},
+		{
+			name: "markdown heading",
+			path: "NOTES.MD",
+			line: "## Usage",
+			expected: map[string][]string{
+				ClassHeading: {"## Usage"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := ForFile(tt.path)
+			require.NotNil(t, h)
+
+			tokens := h.Line(tt.line)
+			assert.Equal(t, tt.expected, classesOf(tokens))
+
+			text := ""
+			for _, token := range tokens {
+				text += token.Text
+			}
+			assert.Equal(t, tt.line, text, "tokens must reproduce the line")
+		})
+	}
+}
+
+func TestLine_MultiLineConstructs(t *testing.T) {
+	h := ForFile("main.go")
+	assert.Equal(t, []Token{{Class: ClassComment, Text: "/* start"}}, h.Line("/* start"))
+	assert.Equal(t, []Token{{Class: ClassComment, Text: "func */"}, {Text: " "}, {Class: ClassKeyword, Text: "var"}}, h.Line("func */ var"))
+
+	py := ForFile("doc.py")
+	py.Line(`x = """doc`)
+	assert.Equal(t, []Token{{Class: ClassString, Text: `def """`}}, py.Line(`def """`))
+}
+
+func TestLine_MarkdownFence(t *testing.T) {
+	h := ForFile("README.md")
+	assert.Equal(t, []Token{{Class: ClassCode, Text: "```go"}}, h.Line("```go"))
+	assert.Equal(t, map[string][]string{ClassKeyword: {"package"}}, classesOf(h.Line("package main")))
+	assert.Equal(t, []Token{{Class: ClassCode, Text: "```"}}, h.Line("```"))
+	assert.Equal(t, map[string][]string{ClassHeading: {"# Title"}}, classesOf(h.Line("# Title")))
+}
+
+func TestUnknownLanguage(t *testing.T) {
+	h := ForFile("notes.txt")
+	assert.Nil(t, h)
+	assert.Equal(t, []Token{{Text: "a < b"}}, h.Line("a < b"))
+	assert.Equal(t, "a &lt; b", Code("notes.txt", "a < b"))
+}
+
+func TestCode(t *testing.T) {
+	assert.Equal(t,
+		`<span class="hl-keyword">package</span> main`+"\n"+`<span class="hl-comment">// &lt;tag&gt;</span>`,
+		Code("/src/main.go", "package main\n// <tag>"))
+}
@@ -0,0 +1,236 @@
+package highlight
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// span is a delimited construct such as a block comment or a multi-line string
+type span struct {
+	open, close string
+	class       string
+	escapes     bool // Whether a backslash escapes the next character
+}
+
+// language describes the lexical syntax of a language
+type language struct {
+	lineComments      []string
+	commentAfterSpace bool   // Line comments only start at the beginning of a word, like # in shell
+	spans             []span // Longest openers first
+	quotes            string // Characters that start and end single-line strings
+	identChars        string // Characters besides letters, digits and _ that continue an identifier
+	keyStrings        bool   // Strings followed by a colon are keys, like in JSON
+	variables         bool   // $name and ${name} are variables, like in shell
+	keywords          set
+	types             set
+	literals          set
+	builtins          set
+	lexLine           func(h *Highlighter, line string, tokens *tokenList) // Replaces the code lexer
+}
+
+// set is a set of words
+type set map[string]bool
+
+// words creates a set of space-separated words
+func words(list string) set {
+	s := make(set)
+	for _, word := range strings.Fields(list) {
+		s[word] = true
+	}
+	return s
+}
+
+// startsLineComment reports whether a line comment starts at line[i]
+func (l *language) startsLineComment(line string, i int) bool {
+	if l.commentAfterSpace && i > 0 && line[i-1] != ' ' && line[i-1] != '\t' {
+		return false
+	}
+	for _, prefix := range l.lineComments {
+		if strings.HasPrefix(line[i:], prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// openSpan returns the span that starts at the beginning of text, if any
+func (l *language) openSpan(text string) (span, bool) {
+	for _, s := range l.spans {
+		if strings.HasPrefix(text, s.open) {
+			return s, true
+		}
+	}
+	return span{}, false
+}
+
+// isIdentPart reports whether c can continue an identifier
+func (l *language) isIdentPart(c byte) bool {
+	return isIdentStart(c) || isDigit(c) || strings.IndexByte(l.identChars, c) >= 0
+}
+
+// classify returns the class of a word, given whether it is followed by an opening parenthesis
+func (l *language) classify(word string, call bool) string {
+	switch {
+	case l.keywords[word]:
+		return ClassKeyword
+	case l.types[word]:
+		return ClassType
+	case l.literals[word]:
+		return ClassLiteral
+	case l.builtins[word]:
+		return ClassBuiltin
+	case call:
+		return ClassFunction
+	default:
+		return ""
+	}
+}
+
+var (
+	goLanguage = &language{
+		lineComments: []string{"//"},
+		spans: []span{
+			{open: "/*", close: "*/", class: ClassComment},
+			{open: "`", close: "`", class: ClassString},
+		},
+		quotes: `"'`,
+		keywords: words(`break case chan const continue default defer else fallthrough for func go goto
+			if import interface map package range return select struct switch type var`),
+		types: words(`any bool byte comparable complex64 complex128 error float32 float64 int int8 int16
+			int32 int64 rune string uint uint8 uint16 uint32 uint64 uintptr`),
+		literals: words(`true false nil iota`),
+		builtins: words(`append cap clear close complex copy delete imag len make max min new panic print
+			println real recover`),
+	}
+
+	typeScriptLanguage = &language{
+		lineComments: []string{"//"},
+		spans: []span{
+			{open: "/*", close: "*/", class: ClassComment},
+			{open: "`", close: "`", class: ClassString, escapes: true},
+		},
+		quotes:     `"'`,
+		identChars: "$",
+		keywords: words(`abstract as async await break case catch class const continue debugger declare
+			default delete do else enum export extends finally for from function get if implements import
+			in instanceof interface keyof let namespace new of private protected public readonly return
+			satisfies set static super switch this throw try type typeof var void while with yield`),
+		types:    words(`any bigint boolean never number object string symbol unknown`),
+		literals: words(`true false null undefined NaN Infinity`),
+		builtins: words(`Array Boolean Date Error JSON Map Math Number Object Promise RegExp Set String
+			Symbol console document exports module process require window`),
+	}
+
+	pythonLanguage = &language{
+		lineComments: []string{"#"},
+		spans: []span{
+			{open: `"""`, close: `"""`, class: ClassString, escapes: true},
+			{open: `'''`, close: `'''`, class: ClassString, escapes: true},
+		},
+		quotes: `"'`,
+		keywords: words(`and as assert async await break case class continue def del elif else except
+			finally for from global if import in is lambda match nonlocal not or pass raise return try
+			while with yield`),
+		literals: words(`True False None`),
+		builtins: words(`abs all any bool dict enumerate Exception filter float getattr hasattr int
+			isinstance KeyError len list map max min open print range repr self set setattr sorted str
+			sum super tuple type TypeError ValueError zip`),
+	}
+
+	jsonLanguage = &language{
+		quotes:     `"`,
+		keyStrings: true,
+		literals:   words(`true false null`),
+	}
+
+	yamlLanguage = &language{
+		lineComments:      []string{"#"},
+		commentAfterSpace: true,
+		quotes:            `"'`,
+		identChars:        "-",
+		literals:          words(`true false null yes no on off True False Null`),
+		lexLine:           lexYAML,
+	}
+
+	shellLanguage = &language{
+		lineComments:      []string{"#"},
+		commentAfterSpace: true,
+		quotes:            `"'`,
+		identChars:        "-",
+		variables:         true,
+		keywords: words(`if then else elif fi case esac for while until do done in function select
+			return exit break continue local export readonly declare unset shift source time`),
+		literals: words(`true false`),
+		builtins: words(`alias cd echo eval exec kill printf pwd read set test trap type wait`),
+	}
+
+	// markdownLanguage gets its lexer in init, as code fences refer back to languageNames
+	markdownLanguage = &language{}
+)
+
+func init() {
+	markdownLanguage.lexLine = lexMarkdown
+}
+
+// languageExtensions maps lower case file extensions to languages
+var languageExtensions = map[string]*language{
+	".go":   goLanguage,
+	".ts":   typeScriptLanguage,
+	".tsx":  typeScriptLanguage,
+	".mts":  typeScriptLanguage,
+	".cts":  typeScriptLanguage,
+	".js":   typeScriptLanguage,
+	".jsx":  typeScriptLanguage,
+	".mjs":  typeScriptLanguage,
+	".cjs":  typeScriptLanguage,
+	".py":   pythonLanguage,
+	".pyi":  pythonLanguage,
+	".json": jsonLanguage,
+	".yaml": yamlLanguage,
+	".yml":  yamlLanguage,
+	".sh":   shellLanguage,
+	".bash": shellLanguage,
+	".zsh":  shellLanguage,
+	".md":   markdownLanguage,
+}
+
+// languageFileNames maps file names without a telling extension to languages
+var languageFileNames = map[string]*language{
+	".bashrc":       shellLanguage,
+	".bash_profile": shellLanguage,
+	".profile":      shellLanguage,
+	".zshrc":        shellLanguage,
+}
+
+// languageNames maps the language names used by Markdown code fences to languages
+var languageNames = map[string]*language{
+	"go":         goLanguage,
+	"golang":     goLanguage,
+	"ts":         typeScriptLanguage,
+	"tsx":        typeScriptLanguage,
+	"typescript": typeScriptLanguage,
+	"js":         typeScriptLanguage,
+	"jsx":        typeScriptLanguage,
+	"javascript": typeScriptLanguage,
+	"py":         pythonLanguage,
+	"python":     pythonLanguage,
+	"json":       jsonLanguage,
+	"yaml":       yamlLanguage,
+	"yml":        yamlLanguage,
+	"sh":         shellLanguage,
+	"bash":       shellLanguage,
+	"shell":      shellLanguage,
+	"zsh":        shellLanguage,
+	"console":    shellLanguage,
+	"md":         markdownLanguage,
+	"markdown":   markdownLanguage,
+}
+
+// languageForFile returns the language of a file, or nil if it isn't known
+func languageForFile(path string) *language {
+	base := filepath.Base(path)
+	if lang := languageFileNames[base]; lang != nil {
+		return lang
+	}
+	return languageExtensions[strings.ToLower(filepath.Ext(base))]
+}
@@ -0,0 +1,124 @@
+package highlight
+
+import "strings"
+
+// lexCode splits a line of a programming or data language into tokens
+func (h *Highlighter) lexCode(line string, tokens *tokenList) {
+	lang := h.lang
+	i := 0
+
+	// Finish a block comment or multi-line string left open by the previous line
+	if h.state.closer != "" {
+		end := findCloser(line, 0, h.state.closer, h.state.escapes)
+		if end < 0 {
+			tokens.add(h.state.class, line)
+			return
+		}
+		tokens.add(h.state.class, line[:end])
+		h.state = lexState{}
+		i = end
+	}
+
+	for i < len(line) {
+		rest := line[i:]
+		c := line[i]
+
+		if lang.startsLineComment(line, i) {
+			tokens.add(ClassComment, rest)
+			return
+		}
+
+		if span, ok := lang.openSpan(rest); ok {
+			end := findCloser(line, i+len(span.open), span.close, span.escapes)
+			if end < 0 {
+				tokens.add(span.class, rest)
+				h.state = lexState{closer: span.close, class: span.class, escapes: span.escapes}
+				return
+			}
+			tokens.add(span.class, line[i:end])
+			i = end
+			continue
+		}
+
+		switch {
+		case strings.IndexByte(lang.quotes, c) >= 0:
+			end := findCloser(line, i+1, string(c), true)
+			if end < 0 {
+				end = len(line)
+			}
+			class := ClassString
+			if lang.keyStrings && strings.HasPrefix(strings.TrimLeft(line[end:], " \t"), ":") {
+				class = ClassKey
+			}
+			tokens.add(class, line[i:end])
+			i = end
+		case lang.variables && c == '$' && i+1 < len(line):
+			end := scanVariable(line, i)
+			tokens.add(ClassVariable, line[i:end])
+			i = end
+		case isDigit(c) && (i == 0 || !lang.isIdentPart(line[i-1])):
+			end := i + 1
+			for end < len(line) && (lang.isIdentPart(line[end]) || line[end] == '.') {
+				end++
+			}
+			tokens.add(ClassNumber, line[i:end])
+			i = end
+		case isIdentStart(c):
+			end := i + 1
+			for end < len(line) && lang.isIdentPart(line[end]) {
+				end++
+			}
+			word := line[i:end]
+			tokens.add(lang.classify(word, strings.HasPrefix(line[end:], "(")), word)
+			i = end
+		default:
+			tokens.add("", line[i:i+1])
+			i++
+		}
+	}
+}
+
+// findCloser returns the index just past the first closer in line at or after from,
+// or -1 if the line doesn't contain it
+func findCloser(line string, from int, closer string, escapes bool) int {
+	for i := from; i < len(line); i++ {
+		if escapes && line[i] == '\\' {
+			i++
+			continue
+		}
+		if strings.HasPrefix(line[i:], closer) {
+			return i + len(closer)
+		}
+	}
+	return -1
+}
+
+// scanVariable returns the index just past a shell variable starting with $ at line[i]
+func scanVariable(line string, i int) int {
+	if line[i+1] == '{' {
+		if end := strings.IndexByte(line[i:], '}'); end >= 0 {
+			return i + end + 1
+		}
+		return len(line)
+	}
+
+	end := i + 1
+	if !isIdentStart(line[end]) {
+		// Special parameters such as $1, $? and $@ are a single character
+		return end + 1
+	}
+	for end < len(line) && (isIdentStart(line[end]) || isDigit(line[end])) {
+		end++
+	}
+	return end
+}
+
+// isDigit reports whether c is an ASCII digit
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// isIdentStart reports whether c can start an identifier
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
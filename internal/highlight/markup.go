@@ -0,0 +1,101 @@
+package highlight
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// yamlKeyPattern matches the key of a YAML mapping entry, after its indentation and list dash
+	yamlKeyPattern = regexp.MustCompile(`^(\s*(?:-\s+)?)([^\s#'"\-][^:#]*?|"[^"]*"|'[^']*')(\s*:)(?:\s|$)`)
+
+	// markdownHeadingPattern matches an ATX heading
+	markdownHeadingPattern = regexp.MustCompile(`^ {0,3}#{1,6}(?:\s|$)`)
+
+	// markdownFencePattern matches the opening or closing line of a fenced code block
+	markdownFencePattern = regexp.MustCompile("^ {0,3}(```+|~~~+)\\s*([\\w+-]*)")
+
+	// markdownListPattern matches the marker of a list item
+	markdownListPattern = regexp.MustCompile(`^(\s*)([-*+]|\d+[.)])(\s)`)
+
+	// markdownInlinePattern matches inline code, strong emphasis and links
+	markdownInlinePattern = regexp.MustCompile("`[^`]+`|\\*\\*[^*]+\\*\\*|__[^_]+__|\\[[^\\]]+\\]\\([^)\\s]+\\)")
+)
+
+// lexYAML splits a line of YAML into tokens, marking the key of a mapping entry
+func lexYAML(h *Highlighter, line string, tokens *tokenList) {
+	match := yamlKeyPattern.FindStringSubmatchIndex(line)
+	if match == nil {
+		h.lexCode(line, tokens)
+		return
+	}
+
+	tokens.add("", line[match[2]:match[3]])
+	tokens.add(ClassKey, line[match[4]:match[5]])
+	tokens.add("", line[match[6]:match[7]])
+	h.lexCode(line[match[7]:], tokens)
+}
+
+// lexMarkdown splits a line of Markdown into tokens. Fenced code blocks are highlighted
+// in the language named after their opening fence.
+func lexMarkdown(h *Highlighter, line string, tokens *tokenList) {
+	if h.state.closer != "" {
+		if match := markdownFencePattern.FindStringSubmatch(line); match != nil &&
+			strings.HasPrefix(match[1], h.state.closer) && match[2] == "" {
+			tokens.add(ClassCode, line)
+			h.state, h.fence = lexState{}, nil
+			return
+		}
+		if h.fence != nil {
+			for _, token := range h.fence.Line(line) {
+				tokens.add(token.Class, token.Text)
+			}
+		} else {
+			tokens.add(ClassCode, line)
+		}
+		return
+	}
+
+	if match := markdownFencePattern.FindStringSubmatch(line); match != nil {
+		tokens.add(ClassCode, line)
+		h.state = lexState{closer: match[1], class: ClassCode}
+		h.fence = ForLanguage(match[2])
+		return
+	}
+
+	switch {
+	case markdownHeadingPattern.MatchString(line):
+		tokens.add(ClassHeading, line)
+		return
+	case strings.HasPrefix(strings.TrimLeft(line, " "), ">"):
+		tokens.add(ClassComment, line)
+		return
+	}
+
+	rest := line
+	if match := markdownListPattern.FindStringSubmatchIndex(line); match != nil {
+		tokens.add("", line[match[2]:match[3]])
+		tokens.add(ClassKeyword, line[match[4]:match[5]])
+		rest = line[match[5]:]
+	}
+	lexMarkdownInline(rest, tokens)
+}
+
+// lexMarkdownInline splits Markdown text into tokens for inline code, emphasis and links
+func lexMarkdownInline(text string, tokens *tokenList) {
+	last := 0
+	for _, match := range markdownInlinePattern.FindAllStringIndex(text, -1) {
+		tokens.add("", text[last:match[0]])
+
+		class := ClassEmphasis
+		switch text[match[0]] {
+		case '`':
+			class = ClassCode
+		case '[':
+			class = ClassLink
+		}
+		tokens.add(class, text[match[0]:match[1]])
+		last = match[1]
+	}
+	tokens.add("", text[last:])
+}
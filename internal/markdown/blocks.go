@@ -0,0 +1,161 @@
+package markdown
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+
+	"github.com/brads3290/cclogviewer/internal/highlight"
+)
+
+var (
+	// fencePattern matches the opening line of a fenced code block
+	fencePattern = regexp.MustCompile("^( {0,3})(```+|~~~+)\\s*([^`\\s]*)")
+
+	// headingPattern matches an ATX heading
+	headingPattern = regexp.MustCompile(`^ {0,3}(#{1,6})(?:\s+(.*?))?(?:\s+#+)?\s*$`)
+
+	// rulePattern matches a thematic break
+	rulePattern = regexp.MustCompile(`^ {0,3}(?:(?:-\s*){3,}|(?:\*\s*){3,}|(?:_\s*){3,})$`)
+
+	// blockquotePattern matches the marker of a blockquote line
+	blockquotePattern = regexp.MustCompile(`^ {0,3}> ?`)
+
+	// listItemPattern matches the first line of a list item
+	listItemPattern = regexp.MustCompile(`^( {0,3})([-*+]|(\d{1,9})[.)])( +|$)`)
+
+	// tableDelimiterPattern matches a cell of the delimiter row of a table
+	tableDelimiterPattern = regexp.MustCompile(`^:?-+:?$`)
+)
+
+// Render converts Markdown text to HTML. Raw HTML in the text is escaped rather than passed
+// through, links are limited to safe schemes, and fenced code is highlighted by its language.
+func Render(text string) string {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+
+	var b strings.Builder
+	renderBlocks(&b, lines, false)
+	return b.String()
+}
+
+// renderBlocks renders lines as block elements. In tight lists, paragraphs are written
+// without <p> tags.
+func renderBlocks(b *strings.Builder, lines []string, tight bool) {
+	for i := 0; i < len(lines); {
+		line := lines[i]
+
+		switch {
+		case strings.TrimSpace(line) == "":
+			i++
+		case fencePattern.MatchString(line):
+			i = renderFence(b, lines, i)
+		case headingPattern.MatchString(line):
+			match := headingPattern.FindStringSubmatch(line)
+			fmt.Fprintf(b, "<h%d>%s</h%d>\n", len(match[1]), renderInline(match[2]), len(match[1]))
+			i++
+		case rulePattern.MatchString(line):
+			b.WriteString("<hr>\n")
+			i++
+		case blockquotePattern.MatchString(line):
+			var quoted []string
+			for ; i < len(lines) && blockquotePattern.MatchString(lines[i]); i++ {
+				quoted = append(quoted, blockquotePattern.ReplaceAllString(lines[i], ""))
+			}
+			b.WriteString("<blockquote>\n")
+			renderBlocks(b, quoted, false)
+			b.WriteString("</blockquote>\n")
+		case listItemPattern.MatchString(line):
+			i = renderList(b, lines, i)
+		case isTableStart(lines, i):
+			i = renderTable(b, lines, i)
+		default:
+			i = renderParagraph(b, lines, i, tight)
+		}
+	}
+}
+
+// startsBlock reports whether a line starts a block that interrupts a paragraph
+func startsBlock(line string) bool {
+	if match := listItemPattern.FindStringSubmatch(line); match != nil {
+		// Only bullets and lists starting at 1 interrupt a paragraph, so that numbers
+		// at the start of a wrapped line don't become lists
+		return match[3] == "" || match[3] == "1"
+	}
+	return fencePattern.MatchString(line) || headingPattern.MatchString(line) ||
+		rulePattern.MatchString(line) || blockquotePattern.MatchString(line)
+}
+
+// renderParagraph renders the paragraph starting at lines[start] and returns the index of
+// the line after it. Line breaks within the paragraph are kept.
+func renderParagraph(b *strings.Builder, lines []string, start int, tight bool) int {
+	end := start + 1
+	for end < len(lines) && strings.TrimSpace(lines[end]) != "" && !startsBlock(lines[end]) && !isTableStart(lines, end) {
+		end++
+	}
+
+	parts := make([]string, 0, end-start)
+	for _, line := range lines[start:end] {
+		parts = append(parts, renderInline(strings.TrimSpace(line)))
+	}
+
+	if tight {
+		b.WriteString(strings.Join(parts, "<br>"))
+		b.WriteString("\n")
+	} else {
+		b.WriteString("<p>")
+		b.WriteString(strings.Join(parts, "<br>"))
+		b.WriteString("</p>\n")
+	}
+	return end
+}
+
+// renderFence renders the fenced code block starting at lines[start] and returns the index
+// of the line after it. An unclosed fence runs to the end of the text.
+func renderFence(b *strings.Builder, lines []string, start int) int {
+	match := fencePattern.FindStringSubmatch(lines[start])
+	indent, fence, info := len(match[1]), match[2], match[3]
+
+	end := start + 1
+	for end < len(lines) {
+		trimmed := strings.TrimSpace(lines[end])
+		if strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]) == "" {
+			break
+		}
+		end++
+	}
+
+	highlighter := highlight.ForLanguage(info)
+	b.WriteString(`<pre class="code-block">`)
+	if info != "" {
+		fmt.Fprintf(b, `<code class="language-%s">`, html.EscapeString(info))
+	} else {
+		b.WriteString(`<code>`)
+	}
+	for i, line := range lines[start+1 : min(end, len(lines))] {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(highlighter.LineHTML(removeIndent(line, indent)))
+	}
+	b.WriteString("</code></pre>\n")
+
+	return end + 1
+}
+
+// removeIndent removes up to n columns of leading spaces and tabs from a line, counting a
+// tab as four columns
+func removeIndent(line string, n int) string {
+	for n > 0 && line != "" {
+		switch line[0] {
+		case ' ':
+			n--
+		case '\t':
+			n -= 4
+		default:
+			return line
+		}
+		line = line[1:]
+	}
+	return line
+}
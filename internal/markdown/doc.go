@@ -0,0 +1,2 @@
+// Package markdown converts Markdown message text to safe HTML.
+package markdown
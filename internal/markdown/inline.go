@@ -0,0 +1,291 @@
+package markdown
+
+import (
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// bareURLPattern matches a URL written without link syntax
+var bareURLPattern = regexp.MustCompile(`^https?://[^\s<>]+`)
+
+// inlineSpecial holds the characters that may start inline markup
+const inlineSpecial = "\\`*_~[!<h"
+
+// renderInline converts the inline Markdown of a line to HTML
+func renderInline(text string) string {
+	var b strings.Builder
+	// Emphasis delimiters known to have no closer in the rest of the line, so that each
+	// unclosed delimiter doesn't search the rest of the line again
+	unclosed := make(map[string]bool)
+
+	for i := 0; i < len(text); {
+		// Copy plain text up to the next character that may start markup
+		next := strings.IndexAny(text[i:], inlineSpecial)
+		if next < 0 {
+			b.WriteString(html.EscapeString(text[i:]))
+			break
+		}
+		b.WriteString(html.EscapeString(text[i : i+next]))
+		i += next
+
+		if n := renderMarkup(&b, text, i, unclosed); n > 0 {
+			i += n
+		} else {
+			b.WriteString(html.EscapeString(text[i : i+1]))
+			i++
+		}
+	}
+
+	return b.String()
+}
+
+// renderMarkup renders the inline markup starting at text[i] and returns its length,
+// or 0 if no markup starts there
+func renderMarkup(b *strings.Builder, text string, i int, unclosed map[string]bool) int {
+	rest := text[i:]
+
+	switch rest[0] {
+	case '\\':
+		if len(rest) > 1 && isASCIIPunct(rest[1]) {
+			b.WriteString(html.EscapeString(rest[1:2]))
+			return 2
+		}
+	case '`':
+		return renderCodeSpan(b, rest)
+	case '*', '_', '~':
+		return renderEmphasis(b, text, i, unclosed)
+	case '!':
+		if strings.HasPrefix(rest, "![") {
+			// Images are shown as links so that rendering never fetches anything
+			if n := renderLink(b, rest[1:]); n > 0 {
+				return n + 1
+			}
+		}
+	case '[':
+		return renderLink(b, rest)
+	case '<':
+		if end := strings.IndexByte(rest, '>'); end > 0 {
+			if target := rest[1:end]; isSafeURL(target) && strings.Contains(target, ":") && !strings.ContainsAny(target, " \t") {
+				writeLink(b, target, html.EscapeString(target))
+				return end + 1
+			}
+		}
+	case 'h':
+		if i > 0 && isWordChar(text[i-1]) {
+			return 0
+		}
+		if match := bareURLPattern.FindString(rest); match != "" {
+			// Punctuation at the end of a bare URL usually ends the sentence instead
+			match = strings.TrimRight(match, ".,:;!?'\")")
+			if isSafeURL(match) {
+				writeLink(b, match, html.EscapeString(match))
+				return len(match)
+			}
+		}
+	}
+	return 0
+}
+
+// renderCodeSpan renders a code span opened by a run of backticks at the start of text
+func renderCodeSpan(b *strings.Builder, text string) int {
+	ticks := len(text) - len(strings.TrimLeft(text, "`"))
+	fence := text[:ticks]
+
+	for search := ticks; search < len(text); {
+		end := strings.Index(text[search:], fence)
+		if end < 0 {
+			break
+		}
+		end += search
+		// The closing run must have exactly as many backticks as the opening one
+		after := end + ticks
+		if after < len(text) && text[after] == '`' {
+			search = after + len(text[after:]) - len(strings.TrimLeft(text[after:], "`"))
+			continue
+		}
+
+		code := text[ticks:end]
+		if len(code) > 1 && code[0] == ' ' && code[len(code)-1] == ' ' && strings.Trim(code, " ") != "" {
+			code = code[1 : len(code)-1]
+		}
+		b.WriteString("<code>")
+		b.WriteString(html.EscapeString(code))
+		b.WriteString("</code>")
+		return after
+	}
+
+	// Without a closing run the backticks are literal
+	b.WriteString(fence)
+	return ticks
+}
+
+// emphasisTags maps emphasis delimiters to their HTML tags, longest delimiters first
+var emphasisTags = []struct {
+	delimiter string
+	tag       string
+}{
+	{"**", "strong"},
+	{"__", "strong"},
+	{"~~", "del"},
+	{"*", "em"},
+	{"_", "em"},
+}
+
+// renderEmphasis renders emphasis opened at text[i]. Delimiters without a closer are added
+// to unclosed, and later ones aren't searched for again.
+func renderEmphasis(b *strings.Builder, text string, i int, unclosed map[string]bool) int {
+	rest := text[i:]
+	for _, emphasis := range emphasisTags {
+		d := emphasis.delimiter
+		if !strings.HasPrefix(rest, d) || len(rest) <= len(d) || isSpace(rest[len(d)]) {
+			continue
+		}
+		// Underscores inside words, as in snake_case, are not emphasis
+		if d[0] == '_' && i > 0 && isWordChar(text[i-1]) {
+			return 0
+		}
+
+		if unclosed[d] {
+			continue
+		}
+		end := findClosingDelimiter(rest, d)
+		if end < 0 {
+			unclosed[d] = true
+			continue
+		}
+		after := end + len(d)
+		if d[0] == '_' && after < len(rest) && isWordChar(rest[after]) {
+			continue
+		}
+
+		b.WriteString("<" + emphasis.tag + ">")
+		b.WriteString(renderInline(rest[len(d):end]))
+		b.WriteString("</" + emphasis.tag + ">")
+		return after
+	}
+	return 0
+}
+
+// findClosingDelimiter returns the index of the delimiter closing emphasis opened at the start
+// of text, or -1. The closer must follow non-space text and, for single-character delimiters,
+// must not be part of a longer run.
+func findClosingDelimiter(text, d string) int {
+	for j := len(d) + 1; j+len(d) <= len(text); j++ {
+		if text[j] == '`' {
+			// Delimiters inside code spans don't count
+			if end := strings.IndexByte(text[j+1:], '`'); end >= 0 {
+				j += end + 1
+				continue
+			}
+		}
+		if !strings.HasPrefix(text[j:], d) || isSpace(text[j-1]) {
+			continue
+		}
+		if len(d) == 1 && j+1 < len(text) && text[j+1] == d[0] {
+			j++
+			continue
+		}
+		return j
+	}
+	return -1
+}
+
+// renderLink renders a [text](url) link at the start of text
+func renderLink(b *strings.Builder, text string) int {
+	// Find the bracket closing the link text, allowing nested brackets
+	depth, closeBracket := 0, -1
+	for j := 0; j < len(text) && closeBracket < 0; j++ {
+		switch text[j] {
+		case '\\':
+			j++
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				closeBracket = j
+			}
+		}
+	}
+	if closeBracket < 0 || closeBracket+1 >= len(text) || text[closeBracket+1] != '(' {
+		return 0
+	}
+
+	// Find the parenthesis closing the destination, allowing balanced parentheses in URLs
+	depth, closeParen := 0, -1
+	for j := closeBracket + 1; j < len(text) && closeParen < 0; j++ {
+		switch text[j] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				closeParen = j
+			}
+		}
+	}
+	if closeParen < 0 {
+		return 0
+	}
+
+	destination := strings.TrimSpace(text[closeBracket+2 : closeParen])
+	if fields := strings.Fields(destination); len(fields) > 0 {
+		// Leave out an optional title
+		destination = fields[0]
+	}
+	destination = strings.TrimSuffix(strings.TrimPrefix(destination, "<"), ">")
+
+	label := renderInline(text[1:closeBracket])
+	if !isSafeURL(destination) {
+		b.WriteString(label)
+	} else {
+		writeLink(b, destination, label)
+	}
+	return closeParen + 1
+}
+
+// writeLink writes a link that opens in a new tab
+func writeLink(b *strings.Builder, target, label string) {
+	b.WriteString(`<a href="`)
+	b.WriteString(html.EscapeString(target))
+	b.WriteString(`" target="_blank" rel="noopener noreferrer">`)
+	b.WriteString(label)
+	b.WriteString(`</a>`)
+}
+
+// isSafeURL reports whether a link target is a web or mail address, or a relative reference
+func isSafeURL(target string) bool {
+	if target == "" {
+		return false
+	}
+	u, err := url.Parse(target)
+	if err != nil {
+		return false
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "", "http", "https", "mailto":
+		return true
+	default:
+		return false
+	}
+}
+
+// isASCIIPunct reports whether c is ASCII punctuation, which a backslash can escape
+func isASCIIPunct(c byte) bool {
+	return c < utf8.RuneSelf && unicode.IsPunct(rune(c)) || strings.IndexByte("$+<=>^`|~", c) >= 0
+}
+
+// isWordChar reports whether c is a letter or digit, counting every byte of a multi-byte
+// character as part of a word
+func isWordChar(c byte) bool {
+	return c >= utf8.RuneSelf || unicode.IsLetter(rune(c)) || unicode.IsDigit(rune(c))
+}
+
+// isSpace reports whether c is a space or tab
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t'
+}
@@ -0,0 +1,137 @@
+package markdown
+
+import (
+	"fmt"
+	"strings"
+)
+
+// listItem is an item of a list with its lines, without the list marker and indentation
+type listItem struct {
+	lines []string
+}
+
+// renderList renders the list starting at lines[start] and returns the index of the line
+// after it. Items separated by a blank line make a loose list, whose paragraphs keep
+// their <p> tags.
+func renderList(b *strings.Builder, lines []string, start int) int {
+	first := listItemPattern.FindStringSubmatch(lines[start])
+	ordered := first[3] != ""
+	kind := listKind(first[2])
+
+	var items []listItem
+	loose := false
+	contentIndent := 0
+	i := start
+	for i < len(lines) {
+		line := lines[i]
+
+		// A new item of the same list
+		if match := listItemPattern.FindStringSubmatch(line); match != nil && listKind(match[2]) == kind &&
+			(len(items) == 0 || len(match[1]) < contentIndent) {
+			markerWidth := len(match[0])
+			if len(match[4]) > 4 {
+				// Content indented by more than four spaces starts one space after the marker
+				markerWidth = len(match[1]) + len(match[2]) + 1
+			}
+			contentIndent = markerWidth
+			items = append(items, listItem{lines: []string{line[min(markerWidth, len(line)):]}})
+			i++
+			continue
+		}
+
+		item := &items[len(items)-1]
+		if strings.TrimSpace(line) == "" {
+			// A blank line continues the list only if more of it follows
+			next := i + 1
+			for next < len(lines) && strings.TrimSpace(lines[next]) == "" {
+				next++
+			}
+			if next == len(lines) || !continuesList(lines[next], kind, contentIndent) {
+				break
+			}
+			loose = true
+			item.lines = append(item.lines, "")
+			i++
+			continue
+		}
+
+		switch {
+		case indentation(line) >= contentIndent:
+			item.lines = append(item.lines, removeIndent(line, contentIndent))
+		case item.lines[len(item.lines)-1] != "" && !startsBlock(line):
+			// A lazy continuation of the item's last paragraph
+			item.lines = append(item.lines, strings.TrimSpace(line))
+		default:
+			return closeList(b, items, ordered, first[3], loose, i)
+		}
+		i++
+	}
+
+	return closeList(b, items, ordered, first[3], loose, i)
+}
+
+// closeList writes the collected items of a list and returns end
+func closeList(b *strings.Builder, items []listItem, ordered bool, startNumber string, loose bool, end int) int {
+	tag := "ul"
+	if ordered {
+		tag = "ol"
+	}
+
+	start := strings.TrimLeft(startNumber, "0")
+	if start == "" {
+		start = "0"
+	}
+	if ordered && start != "1" {
+		fmt.Fprintf(b, "<ol start=\"%s\">\n", start)
+	} else {
+		fmt.Fprintf(b, "<%s>\n", tag)
+	}
+
+	for _, item := range items {
+		b.WriteString("<li>")
+		renderBlocks(b, trimTrailingBlank(item.lines), !loose)
+		b.WriteString("</li>\n")
+	}
+	fmt.Fprintf(b, "</%s>\n", tag)
+
+	return end
+}
+
+// continuesList reports whether a line after a blank line still belongs to the list
+func continuesList(line, kind string, contentIndent int) bool {
+	if indentation(line) >= contentIndent {
+		return true
+	}
+	match := listItemPattern.FindStringSubmatch(line)
+	return match != nil && listKind(match[2]) == kind
+}
+
+// listKind returns the kind of a list marker: the bullet character, or the delimiter of an
+// ordered marker. Items of different kinds belong to different lists.
+func listKind(marker string) string {
+	return marker[len(marker)-1:]
+}
+
+// indentation returns the number of leading spaces of a line, counting a tab as four
+func indentation(line string) int {
+	n := 0
+	for _, c := range line {
+		switch c {
+		case ' ':
+			n++
+		case '\t':
+			n += 4
+		default:
+			return n
+		}
+	}
+	return n
+}
+
+// trimTrailingBlank removes blank lines from the end of lines
+func trimTrailingBlank(lines []string) []string {
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
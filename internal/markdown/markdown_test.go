@@ -0,0 +1,109 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "paragraphs keep line breaks",
+			input:    "First line\nsecond line\n\nNext paragraph",
+			expected: "<p>First line<br>second line</p>\n<p>Next paragraph</p>\n",
+		},
+		{
+			name:     "headings and rules",
+			input:    "# Title\n## Section ##\n---",
+			expected: "<h1>Title</h1>\n<h2>Section</h2>\n<hr>\n",
+		},
+		{
+			name:     "raw html is escaped",
+			input:    `<script>alert("x")</script> & <b>bold</b>`,
+			expected: "<p>&lt;script&gt;alert(&#34;x&#34;)&lt;/script&gt; &amp; &lt;b&gt;bold&lt;/b&gt;</p>\n",
+		},
+		{
+			name:     "inline markup",
+			input:    "Use `a < b` with **bold**, *em*, ~~old~~ and snake_case_name",
+			expected: "<p>Use <code>a &lt; b</code> with <strong>bold</strong>, <em>em</em>, <del>old</del> and snake_case_name</p>\n",
+		},
+		{
+			name:     "escaped markup",
+			input:    `\*not em\* and 2 * 3 * 4`,
+			expected: "<p>*not em* and 2 * 3 * 4</p>\n",
+		},
+		{
+			name:     "links",
+			input:    "See [the docs](https://example.com/a_(b) \"Title\") or <https://x.io> or https://y.io/p.",
+			expected: `<p>See <a href="https://example.com/a_(b)" target="_blank" rel="noopener noreferrer">the docs</a> or <a href="https://x.io" target="_blank" rel="noopener noreferrer">https://x.io</a> or <a href="https://y.io/p" target="_blank" rel="noopener noreferrer">https://y.io/p</a>.</p>` + "\n",
+		},
+		{
+			name:     "unsafe links are text",
+			input:    "[click](javascript:alert(1)) ![img](data:image/png;base64,x)",
+			expected: "<p>click img</p>\n",
+		},
+		{
+			name:     "tight list with nesting",
+			input:    "- one\n- two\n  1. nested\n  2. more\n- three",
+			expected: "<ul>\n<li>one\n</li>\n<li>two\n<ol>\n<li>nested\n</li>\n<li>more\n</li>\n</ol>\n</li>\n<li>three\n</li>\n</ul>\n",
+		},
+		{
+			name:     "loose ordered list",
+			input:    "3. first\n\n4. second\n\nAfter",
+			expected: "<ol start=\"3\">\n<li><p>first</p>\n</li>\n<li><p>second</p>\n</li>\n</ol>\n<p>After</p>\n",
+		},
+		{
+			name:     "numbers do not interrupt paragraphs",
+			input:    "We need\n2. things",
+			expected: "<p>We need<br>2. things</p>\n",
+		},
+		{
+			name:     "blockquote",
+			input:    "> quoted **text**\n> more",
+			expected: "<blockquote>\n<p>quoted <strong>text</strong><br>more</p>\n</blockquote>\n",
+		},
+		{
+			name:     "table",
+			input:    "| Name | Size |\n|:-----|-----:|\n| a `x|y` | 1 |\n| b \\| c |",
+			expected: "<table class=\"markdown-table\">\n<thead>\n<tr><th style=\"text-align: left\">Name</th><th style=\"text-align: right\">Size</th></tr>\n</thead>\n<tbody>\n<tr><td style=\"text-align: left\">a <code>x|y</code></td><td style=\"text-align: right\">1</td></tr>\n<tr><td style=\"text-align: left\">b | c</td><td style=\"text-align: right\"></td></tr>\n</tbody>\n</table>\n",
+		},
+		{
+			name:     "fenced code is highlighted",
+			input:    "```go\nreturn \"<x>\"\n```\nafter",
+			expected: "<pre class=\"code-block\"><code class=\"language-go\"><span class=\"hl-keyword\">return</span> <span class=\"hl-string\">&#34;&lt;x&gt;&#34;</span></code></pre>\n<p>after</p>\n",
+		},
+		{
+			name:     "fenced code of unknown language",
+			input:    "~~~\n<b> *not em*\n~~~",
+			expected: "<pre class=\"code-block\"><code>&lt;b&gt; *not em*</code></pre>\n",
+		},
+		{
+			name:     "unclosed fence runs to the end",
+			input:    "```\ncode",
+			expected: "<pre class=\"code-block\"><code>code</code></pre>\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Render(tt.input))
+		})
+	}
+}
+
+func TestRender_UnclosedEmphasis(t *testing.T) {
+	input := strings.Repeat("*a _b ", 10000)
+
+	start := time.Now()
+	output := Render(input)
+
+	assert.NotContains(t, output, "<em>")
+	assert.Less(t, time.Since(start), time.Second, "Expected unclosed delimiters not to be searched for again")
+}
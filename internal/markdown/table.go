@@ -0,0 +1,100 @@
+package markdown
+
+import (
+	"strings"
+)
+
+// isTableStart reports whether a table starts at lines[i]: a header row followed by a
+// delimiter row with the same number of cells
+func isTableStart(lines []string, i int) bool {
+	if i+1 >= len(lines) || !strings.Contains(lines[i], "|") {
+		return false
+	}
+	header, delimiter := splitRow(lines[i]), splitRow(lines[i+1])
+	if len(header) != len(delimiter) {
+		return false
+	}
+	for _, cell := range delimiter {
+		if !tableDelimiterPattern.MatchString(cell) {
+			return false
+		}
+	}
+	return true
+}
+
+// renderTable renders the table starting at lines[start] and returns the index of the line
+// after it
+func renderTable(b *strings.Builder, lines []string, start int) int {
+	header := splitRow(lines[start])
+	aligns := make([]string, len(header))
+	for i, cell := range splitRow(lines[start+1]) {
+		switch {
+		case strings.HasPrefix(cell, ":") && strings.HasSuffix(cell, ":"):
+			aligns[i] = "center"
+		case strings.HasSuffix(cell, ":"):
+			aligns[i] = "right"
+		case strings.HasPrefix(cell, ":"):
+			aligns[i] = "left"
+		}
+	}
+
+	b.WriteString("<table class=\"markdown-table\">\n<thead>\n")
+	writeRow(b, "th", header, aligns)
+	b.WriteString("</thead>\n<tbody>\n")
+
+	end := start + 2
+	for ; end < len(lines) && strings.TrimSpace(lines[end]) != "" && strings.Contains(lines[end], "|"); end++ {
+		writeRow(b, "td", splitRow(lines[end]), aligns)
+	}
+	b.WriteString("</tbody>\n</table>\n")
+
+	return end
+}
+
+// writeRow writes a table row, padding or cutting its cells to the number of columns
+func writeRow(b *strings.Builder, tag string, cells []string, aligns []string) {
+	b.WriteString("<tr>")
+	for i, align := range aligns {
+		b.WriteString("<" + tag)
+		if align != "" {
+			b.WriteString(` style="text-align: ` + align + `"`)
+		}
+		b.WriteString(">")
+		if i < len(cells) {
+			b.WriteString(renderInline(cells[i]))
+		}
+		b.WriteString("</" + tag + ">")
+	}
+	b.WriteString("</tr>\n")
+}
+
+// splitRow splits a table row into trimmed cells. Pipes escaped with a backslash or inside
+// code spans don't separate cells.
+func splitRow(line string) []string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+	if strings.HasSuffix(line, "|") && !strings.HasSuffix(line, `\|`) {
+		line = line[:len(line)-1]
+	}
+
+	var cells []string
+	var cell strings.Builder
+	inCode := false
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case c == '\\' && i+1 < len(line) && line[i+1] == '|':
+			cell.WriteByte('|')
+			i++
+		case c == '`':
+			inCode = !inCode
+			cell.WriteByte(c)
+		case c == '|' && !inCode:
+			cells = append(cells, strings.TrimSpace(cell.String()))
+			cell.Reset()
+		default:
+			cell.WriteByte(c)
+		}
+	}
+	return append(cells, strings.TrimSpace(cell.String()))
+}
@@ -0,0 +1,18 @@
+package models
+
+// ContentBlock is a single block of message content, kept in its original order.
+type ContentBlock struct {
+	Type     string     // Content type, e.g. "text", "tool_use" or "thinking"
+	Text     string     // Text of text and thinking blocks
+	ToolCall *ToolCall  // Tool call of tool_use blocks, pointing into the entry's ToolCalls
+	Image    *ImageData // Image of image blocks
+}
+
+// ImageData is an image embedded in message content.
+type ImageData struct {
+	MediaType  string // MIME type, e.g. "image/png"
+	Data       string // Base64 encoded image data, empty when the image is omitted
+	Size       int    // Decoded size in bytes
+	Omitted    bool   // True if the image can't be embedded in the page
+	OmitReason string // Why the image was omitted
+}
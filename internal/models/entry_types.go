@@ -0,0 +1,23 @@
+package models
+
+// SystemInfo holds the details of a system entry, such as hook output or an API error.
+type SystemInfo struct {
+	Subtype string // Kind of system entry, e.g. "informational" or "compact_boundary"
+	Level   string // Severity, e.g. "info", "warning" or "error"
+	Content string // Message text
+}
+
+// FileSnapshot is a file history checkpoint recorded before a message changed files.
+type FileSnapshot struct {
+	MessageID string        // UUID of the message the snapshot belongs to
+	IsUpdate  bool          // True if the snapshot updates an earlier one for the same message
+	Files     []TrackedFile // Tracked files sorted by path
+}
+
+// TrackedFile is a single file backed up by a file history snapshot.
+type TrackedFile struct {
+	Path           string
+	BackupFileName string // Empty if the file did not exist yet
+	Version        int
+	BackupTime     string
+}
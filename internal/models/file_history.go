@@ -0,0 +1,105 @@
+package models
+
+import (
+	"fmt"
+	"html/template"
+	"path/filepath"
+	"strings"
+
+	"github.com/brads3290/cclogviewer/internal/constants"
+)
+
+// FileOperation is a single Read, Write, Edit or MultiEdit call on a file.
+type FileOperation struct {
+	ToolCallID   string
+	Tool         string // Name of the tool that was called
+	Timestamp    string
+	CWD          string // Working directory of the call
+	Succeeded    bool   // False if the call failed or has no result
+	IsSidechain  bool   // True if a subagent made the call
+	Content      string // File content after the call, if ContentKnown
+	ContentKnown bool   // Whether the whole file content after the call is known
+
+	Written string     // Content written by a Write call
+	Edits   []FileEdit // Replacements made by an Edit or MultiEdit call, in order
+}
+
+// FileEdit is a single string replacement of an Edit or MultiEdit call.
+type FileEdit struct {
+	OldString  string
+	NewString  string
+	ReplaceAll bool
+}
+
+// FileHistory is everything a session did to a single file, in time order.
+type FileHistory struct {
+	Path           string
+	Operations     []FileOperation
+	InitialContent string        // File content before the first change, if InitialKnown
+	InitialKnown   bool          // Whether the whole file content before the first change is known
+	Created        bool          // True if the session created the file
+	CumulativeDiff template.HTML // Diff of all changes, empty unless the contents before and after are known
+}
+
+// Changed reports whether any call succeeded in changing the file
+func (h *FileHistory) Changed() bool {
+	for _, op := range h.Operations {
+		if op.Succeeded && op.Tool != constants.ToolNameRead {
+			return true
+		}
+	}
+	return false
+}
+
+// RelativePath returns the path of the file relative to the working directory of each call
+// that changed it, with forward slashes. Files outside a working directory, or whose path is
+// different relative to the working directories of different calls, have no relative path.
+func (h *FileHistory) RelativePath() (string, error) {
+	if !filepath.IsAbs(h.Path) {
+		return relativePath(h.Path, "")
+	}
+
+	var path, pathCWD string
+	for _, op := range h.Operations {
+		if !op.Succeeded || op.Tool == constants.ToolNameRead {
+			continue
+		}
+		if op.CWD == "" {
+			return "", fmt.Errorf("working directory is unknown")
+		}
+		rel, err := filepath.Rel(op.CWD, h.Path)
+		if err != nil {
+			return "", err
+		}
+		rel, err = relativePath(rel, op.CWD)
+		if err != nil {
+			return "", err
+		}
+		if path != "" && rel != path {
+			return "", fmt.Errorf("changed from different working directories %s and %s", pathCWD, op.CWD)
+		}
+		path, pathCWD = rel, op.CWD
+	}
+	if path == "" {
+		return "", fmt.Errorf("working directory is unknown")
+	}
+	return path, nil
+}
+
+// relativePath converts a path relative to a working directory to forward slashes, failing
+// if it leaves the directory
+func relativePath(path, cwd string) (string, error) {
+	path = filepath.ToSlash(path)
+	if path == ".." || strings.HasPrefix(path, "../") {
+		return "", fmt.Errorf("outside the working directory %s", cwd)
+	}
+	return path, nil
+}
+
+// Final returns the last operation on the file
+func (h *FileHistory) Final() *FileOperation {
+	if len(h.Operations) == 0 {
+		return nil
+	}
+	return &h.Operations[len(h.Operations)-1]
+}
@@ -20,6 +20,11 @@ type LogEntry struct {
 	Timestamp     string          `json:"timestamp"`
 	IsMeta        bool            `json:"isMeta"`
 	ToolUseResult interface{}     `json:"toolUseResult"`
+	AgentID       string          `json:"agentId"`
+	Summary       string          `json:"summary"`  // Summary text of summary entries
+	LeafUUID      string          `json:"leafUuid"` // Last message covered by a summary entry
+
+	Raw json.RawMessage `json:"-"` // The complete JSON line, for entry types without dedicated fields
 }
 
 // TokenMetrics groups token usage and counting metrics.
@@ -28,6 +33,7 @@ type TokenMetrics struct {
 	TotalTokens         int // Running total of all tokens up to this message
 	InputTokens         int // Input tokens from usage
 	OutputTokens        int // Output tokens from usage
+	ThinkingTokens      int // Estimated tokens spent on extended thinking (included in OutputTokens)
 	CacheReadTokens     int // Cache read tokens from usage
 	CacheCreationTokens int // Cache creation tokens from usage
 }
@@ -49,23 +55,47 @@ type ProcessedEntry struct {
 	Timestamp    string
 	RawTimestamp string // Keep the raw timestamp for comparisons
 	Role         string
-	Content      string // Raw content, HTML escaping happens in templates
+	Content      string         // Raw content, HTML escaping happens in templates
+	Blocks       []ContentBlock // Content blocks in their original order
 
 	// Relationships
 	Children []*ProcessedEntry
 	Depth    int
+	AgentID  string // Subagent that wrote this sidechain entry
 
 	// Tool-related
-	ToolCalls    []ToolCall
-	IsToolResult bool
-	ToolResultID string // For matching tool results to tool calls
+	ToolCalls         []ToolCall
+	ToolResults       []*ProcessedEntry // One entry per tool_result block of a user message
+	IsToolResult      bool
+	ToolResultID      string      // For matching tool results to tool calls
+	IsUnmatchedResult bool        // True if no tool call was found for this tool result
+	ToolUseResult     interface{} // Structured result payload of tool result entries
 
 	// Embedded structs for grouping
 	TokenMetrics
 	CommandInfo
 
+	// Summaries whose leaf is this entry, shown as compaction markers after it
+	Summaries []Summary
+
+	// Non-message entries
+	System   *SystemInfo   // Set for system entries
+	Snapshot *FileSnapshot // Set for file history snapshots
+	RawJSON  string        // Indented JSON of entry types without a handler
+
 	// Flags
 	IsSidechain     bool
 	IsError         bool
 	IsCaveatMessage bool // True if this is a special caveat message from local commands
 }
+
+// UnmatchedResults returns the tool results of this entry that were not attached to a tool call.
+func (e *ProcessedEntry) UnmatchedResults() []*ProcessedEntry {
+	var unmatched []*ProcessedEntry
+	for _, result := range e.ToolResults {
+		if result.IsUnmatchedResult {
+			unmatched = append(unmatched, result)
+		}
+	}
+	return unmatched
+}
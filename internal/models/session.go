@@ -0,0 +1,15 @@
+package models
+
+// Summary is a session summary written by Claude Code.
+type Summary struct {
+	Text     string // Summary text, also used as the session title
+	LeafUUID string // UUID of the last message the summary covers
+}
+
+// Session is a fully processed log file.
+type Session struct {
+	Title     string            // Session title, empty if the log has no summaries
+	Summaries []Summary         // Summary entries in file order
+	Entries   []*ProcessedEntry // Root conversation entries
+	Files     []*FileHistory    // Files the session used, in the order they were first used
+}
@@ -6,14 +6,31 @@ import "html/template"
 type ToolCall struct {
 	ID                  string
 	Name                string
+	MCPServer           string // Server of an MCP tool, shown as a badge; empty for other tools
+	MCPTool             string // Name of an MCP tool without its server
 	Description         string
 	Input               template.HTML
 	RawInput            interface{}       // Raw input data before formatting
 	CompactView         template.HTML     // Optional compact view for specific tools
+	Output              template.HTML     // Formatted result of the call
+	Inline              bool              // Whether Output shows the whole call, displayed open without the collapsible details
 	Result              *ProcessedEntry   // Tool result entry
 	TaskEntries         []*ProcessedEntry // For Task tool - sidechain entries
+	LinkMethod          string            // How TaskEntries were linked, e.g. "agent-id" or "text"
+	LinkConfidence      string            // How certain the TaskEntries link is: "high", "medium" or "low"
 	IsInterrupted       bool              // Whether the tool was interrupted by the user
 	HasMissingResult    bool              // Whether the tool result is missing
 	HasMissingSidechain bool              // Whether Task tool sidechain conversation is missing
 	CWD                 string            // Current working directory when the tool was called
+	EditLines           []int             // File line each edit of an Edit or MultiEdit call starts at, 0 if unknown
+}
+
+// FilePath returns the file_path input of the tool call, or an empty string if it has none
+func (t ToolCall) FilePath() string {
+	input, ok := t.RawInput.(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	path, _ := input["file_path"].(string)
+	return path
 }
@@ -2,52 +2,109 @@ package parser
 
 import (
 	"bufio"
+	"bytes"
 	"encoding/json"
 	"github.com/brads3290/cclogviewer/internal/constants"
 	"github.com/brads3290/cclogviewer/internal/debug"
 	"github.com/brads3290/cclogviewer/internal/models"
+	"io"
 	"log"
-	"math"
 	"os"
 )
 
-// ReadJSONLFile reads a JSONL file and returns a slice of LogEntry
-func ReadJSONLFile(filename string) ([]models.LogEntry, error) {
-	file, err := os.Open(filename)
-	if err != nil {
-		return nil, err
+// Record is a single parsed log entry together with its position in the source.
+type Record struct {
+	Entry  models.LogEntry
+	Line   int   // 1-based line number of the entry
+	Offset int64 // Byte offset of the start of the line
+}
+
+// Reader reads log entries from a JSONL stream one line at a time.
+type Reader struct {
+	r       *bufio.Reader
+	line    int
+	offset  int64
+	skipped int
+}
+
+// NewReader creates a new JSONL reader
+func NewReader(r io.Reader) *Reader {
+	return &Reader{
+		r: bufio.NewReaderSize(r, constants.DefaultScannerBufferSize),
 	}
-	defer file.Close()
+}
 
-	var entries []models.LogEntry
-	scanner := bufio.NewScanner(file)
-	// Set buffer with no maximum size limit
-	buf := make([]byte, 0, constants.DefaultScannerBufferSize)
-	scanner.Buffer(buf, math.MaxInt)
-
-	lineNum := 0
-	for scanner.Scan() {
-		lineNum++
-		var entry models.LogEntry
-		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
-			if debug.Enabled {
-				log.Printf("Error parsing line %d: %v", lineNum, err)
-			}
-			continue
+// Next returns the next entry in the stream, or io.EOF when the stream is exhausted.
+// Lines that are not valid JSON are skipped.
+func (r *Reader) Next() (*Record, error) {
+	for {
+		line, err := r.r.ReadBytes('\n')
+		if len(line) == 0 && err != nil {
+			return nil, err
 		}
 
-		// Skip summary messages
-		if entry.Type == constants.EntryTypeSummary {
+		r.line++
+		offset := r.offset
+		r.offset += int64(len(line))
+
+		trimmed := bytes.TrimSpace(line)
+		if len(trimmed) > 0 {
+			var entry models.LogEntry
+			jsonErr := json.Unmarshal(trimmed, &entry)
+			if jsonErr == nil {
+				entry.Raw = trimmed
+				return &Record{Entry: entry, Line: r.line, Offset: offset}, nil
+			}
+
+			r.skipped++
 			if debug.Enabled {
-				log.Printf("Skipping summary message at line %d", lineNum)
+				log.Printf("Error parsing line %d: %v", r.line, jsonErr)
 			}
-			continue
 		}
 
-		entries = append(entries, entry)
+		if err != nil {
+			return nil, err
+		}
 	}
+}
 
-	if err := scanner.Err(); err != nil {
+// Skipped returns the number of malformed lines skipped so far
+func (r *Reader) Skipped() int {
+	return r.skipped
+}
+
+// StreamJSONLFile calls fn for every entry in a JSONL file without loading the whole file
+func StreamJSONLFile(filename string, fn func(*Record) error) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	reader := NewReader(file)
+	for {
+		record, err := reader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := fn(record); err != nil {
+			return err
+		}
+	}
+}
+
+// ReadJSONLFile reads a JSONL file and returns a slice of LogEntry
+func ReadJSONLFile(filename string) ([]models.LogEntry, error) {
+	var entries []models.LogEntry
+	err := StreamJSONLFile(filename, func(record *Record) error {
+		entries = append(entries, record.Entry)
+		return nil
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -1,6 +1,8 @@
 package parser
 
 import (
+	"errors"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -96,14 +98,14 @@ func TestReadJSONLFile_SpecialCharacters(t *testing.T) {
 	}
 }
 
-func TestReadJSONLFile_SkipsSummaryMessages(t *testing.T) {
+func TestReadJSONLFile_KeepsSummaryMessages(t *testing.T) {
 	// Create a temporary file with summary messages
 	tmpfile, err := os.CreateTemp("", "test_summary_*.jsonl")
 	require.NoError(t, err)
 	defer os.Remove(tmpfile.Name())
 
-	content := `{"uuid":"msg-001","type":"message","timestamp":"2024-01-01T10:00:00Z","message":{"content":"Regular message"}}
-{"uuid":"sum-001","type":"summary","timestamp":"2024-01-01T10:00:01Z","message":{"content":"Summary message"}}
+	content := `{"type":"summary","summary":"Fix login bug","leafUuid":"msg-002"}
+{"uuid":"msg-001","type":"message","timestamp":"2024-01-01T10:00:00Z","message":{"content":"Regular message"}}
 {"uuid":"msg-002","type":"message","timestamp":"2024-01-01T10:00:02Z","message":{"content":"Another regular message"}}`
 
 	_, err = tmpfile.WriteString(content)
@@ -112,12 +114,11 @@ func TestReadJSONLFile_SkipsSummaryMessages(t *testing.T) {
 
 	entries, err := ReadJSONLFile(tmpfile.Name())
 	require.NoError(t, err)
-	assert.Len(t, entries, 2, "Should skip summary messages")
+	require.Len(t, entries, 3, "Should keep summary messages")
 
-	// Verify no summary messages in result
-	for _, entry := range entries {
-		assert.NotEqual(t, "summary", entry.Type)
-	}
+	assert.Equal(t, "summary", entries[0].Type)
+	assert.Equal(t, "Fix login bug", entries[0].Summary)
+	assert.Equal(t, "msg-002", entries[0].LeafUUID)
 }
 
 func TestReadJSONLFile_LargeLines(t *testing.T) {
@@ -143,6 +144,57 @@ func TestReadJSONLFile_LargeLines(t *testing.T) {
 	assert.Contains(t, message, largeContent)
 }
 
+func TestReader_Positions(t *testing.T) {
+	content := "{\"uuid\":\"msg-001\",\"type\":\"user\"}\n" +
+		"{not json\n" +
+		"\n" +
+		"{\"uuid\":\"msg-002\",\"type\":\"assistant\"}\r\n" +
+		"{\"uuid\":\"msg-003\",\"type\":\"user\"}"
+
+	reader := NewReader(strings.NewReader(content))
+
+	var records []*Record
+	for {
+		record, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		records = append(records, record)
+	}
+
+	require.Len(t, records, 3)
+	assert.Equal(t, "msg-001", records[0].Entry.UUID)
+	assert.Equal(t, 1, records[0].Line)
+	assert.Equal(t, int64(0), records[0].Offset)
+
+	assert.Equal(t, "msg-002", records[1].Entry.UUID)
+	assert.Equal(t, 4, records[1].Line)
+	assert.Equal(t, int64(strings.Index(content, `{"uuid":"msg-002"`)), records[1].Offset)
+
+	// The last line has no trailing newline
+	assert.Equal(t, "msg-003", records[2].Entry.UUID)
+	assert.Equal(t, 5, records[2].Line)
+	assert.Equal(t, int64(strings.Index(content, `{"uuid":"msg-003"`)), records[2].Offset)
+
+	assert.Equal(t, 1, reader.Skipped())
+}
+
+func TestStreamJSONLFile_StopsOnCallbackError(t *testing.T) {
+	testDir := getTestDataDir(t)
+	path := filepath.Join(testDir, "fixtures/valid/simple.jsonl")
+
+	stop := errors.New("stop")
+	calls := 0
+	err := StreamJSONLFile(path, func(record *Record) error {
+		calls++
+		return stop
+	})
+
+	assert.ErrorIs(t, err, stop)
+	assert.Equal(t, 1, calls)
+}
+
 func BenchmarkReadJSONLFile(b *testing.B) {
 	// Create a benchmark file with many entries
 	tmpfile, err := os.CreateTemp("", "bench_*.jsonl")
@@ -0,0 +1,89 @@
+package parser
+
+import (
+	"errors"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/brads3290/cclogviewer/internal/constants"
+)
+
+// errStopStream stops streaming a file once the needed entry has been read
+var errStopStream = errors.New("stop stream")
+
+// FindSubagentFiles returns the subagent transcripts of a session. Claude Code writes them
+// as agent-*.jsonl files either next to the session file or in a subagents directory named
+// after the session. Sibling files are only returned if they belong to sessionID, which
+// defaults to the session file name.
+func FindSubagentFiles(sessionFile, sessionID string) ([]string, error) {
+	dir := filepath.Dir(sessionFile)
+	base := strings.TrimSuffix(filepath.Base(sessionFile), filepath.Ext(sessionFile))
+	if sessionID == "" {
+		sessionID = base
+	}
+
+	pattern := constants.SubagentFilePrefix + "*" + constants.JSONLFileExtension
+
+	// Everything in the session's own subagent directory belongs to it
+	files, err := filepath.Glob(filepath.Join(dir, base, constants.SubagentDirectoryName, pattern))
+	if err != nil {
+		return nil, err
+	}
+
+	siblings, err := filepath.Glob(filepath.Join(dir, pattern))
+	if err != nil {
+		return nil, err
+	}
+	for _, sibling := range siblings {
+		if sibling == sessionFile {
+			continue
+		}
+
+		id, err := readSessionID(sibling)
+		if err != nil {
+			return nil, err
+		}
+		if id == sessionID {
+			files = append(files, sibling)
+		}
+	}
+
+	sort.Strings(files)
+	return files, nil
+}
+
+// StreamSubagentFile calls fn for every entry in a subagent transcript. Entries are marked as
+// sidechain entries and get the agent ID from the file name if they don't record one.
+func StreamSubagentFile(filename string, fn func(*Record) error) error {
+	agentID := SubagentID(filename)
+	return StreamJSONLFile(filename, func(record *Record) error {
+		record.Entry.IsSidechain = true
+		if record.Entry.AgentID == "" {
+			record.Entry.AgentID = agentID
+		}
+		return fn(record)
+	})
+}
+
+// SubagentID returns the agent ID encoded in a subagent transcript's file name
+func SubagentID(filename string) string {
+	base := strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename))
+	return strings.TrimPrefix(base, constants.SubagentFilePrefix)
+}
+
+// readSessionID returns the session ID of the first entry that records one
+func readSessionID(filename string) (string, error) {
+	var sessionID string
+	err := StreamJSONLFile(filename, func(record *Record) error {
+		if record.Entry.SessionID == "" {
+			return nil
+		}
+		sessionID = record.Entry.SessionID
+		return errStopStream
+	})
+	if err != nil && err != errStopStream {
+		return "", err
+	}
+	return sessionID, nil
+}
@@ -0,0 +1,52 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+func TestFindSubagentFiles(t *testing.T) {
+	dir := t.TempDir()
+	session := filepath.Join(dir, "session-1.jsonl")
+	writeFile(t, session, `{"uuid":"msg-1","type":"user","sessionId":"session-1"}`+"\n")
+	writeFile(t, filepath.Join(dir, "agent-aaa.jsonl"), `{"uuid":"a-1","type":"user","sessionId":"session-1","isSidechain":true}`+"\n")
+	writeFile(t, filepath.Join(dir, "agent-bbb.jsonl"), `{"uuid":"b-1","type":"user","sessionId":"session-2","isSidechain":true}`+"\n")
+	writeFile(t, filepath.Join(dir, "session-1", "subagents", "agent-ccc.jsonl"), `{"uuid":"c-1","type":"user"}`+"\n")
+
+	files, err := FindSubagentFiles(session, "")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		filepath.Join(dir, "agent-aaa.jsonl"),
+		filepath.Join(dir, "session-1", "subagents", "agent-ccc.jsonl"),
+	}, files)
+}
+
+func TestStreamSubagentFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "agent-abc123.jsonl")
+	writeFile(t, path,
+		`{"uuid":"a-1","type":"user","sessionId":"session-1"}`+"\n"+
+			`{"uuid":"a-2","type":"assistant","sessionId":"session-1","agentId":"explicit"}`+"\n")
+
+	var records []*Record
+	err := StreamSubagentFile(path, func(record *Record) error {
+		records = append(records, record)
+		return nil
+	})
+	require.NoError(t, err)
+
+	require.Len(t, records, 2)
+	assert.True(t, records[0].Entry.IsSidechain)
+	assert.Equal(t, "abc123", records[0].Entry.AgentID)
+	assert.Equal(t, "explicit", records[1].Entry.AgentID)
+}
@@ -0,0 +1,2 @@
+// Package patch exports the file changes of a session as a git-style patch.
+package patch
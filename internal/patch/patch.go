@@ -0,0 +1,75 @@
+package patch
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/brads3290/cclogviewer/internal/constants"
+	"github.com/brads3290/cclogviewer/internal/models"
+	"github.com/brads3290/cclogviewer/internal/processor/tools/diff"
+)
+
+// Skipped is a changed file left out of a patch.
+type Skipped struct {
+	Path   string
+	Reason string
+}
+
+// Write writes the changes a session made to its files as a patch that git apply accepts.
+// Paths are relative to the working directory of the first call that changed each file.
+// Files whose changes can't be reconstructed are left out and returned.
+func Write(w io.Writer, files []*models.FileHistory) ([]Skipped, error) {
+	var skipped []Skipped
+
+	for _, file := range files {
+		if !file.Changed() {
+			continue
+		}
+
+		path, err := file.RelativePath()
+		if err != nil {
+			skipped = append(skipped, Skipped{Path: file.Path, Reason: err.Error()})
+			continue
+		}
+		if !file.InitialKnown {
+			skipped = append(skipped, Skipped{Path: file.Path, Reason: "content before the first change is unknown"})
+			continue
+		}
+		final := file.Final()
+		if !final.ContentKnown {
+			skipped = append(skipped, Skipped{Path: file.Path, Reason: "content after the last change is unknown"})
+			continue
+		}
+
+		hunks := diff.ComputeFilePatch(file.InitialContent, final.Content, constants.DiffContextLines)
+		if hunks == "" && !file.Created {
+			continue
+		}
+		if err := WriteFile(w, path, file.Created, hunks); err != nil {
+			return skipped, err
+		}
+	}
+
+	return skipped, nil
+}
+
+// WriteFile writes the patch of a single file, given its path relative to the patch root
+// and hunks from diff.ComputeFilePatch
+func WriteFile(w io.Writer, path string, created bool, hunks string) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "diff --git a/%s b/%s\n", path, path)
+	oldName := "a/" + path
+	if created {
+		b.WriteString("new file mode 100644\n")
+		oldName = "/dev/null"
+	}
+	if hunks != "" {
+		fmt.Fprintf(&b, "--- %s\n+++ b/%s\n", oldName, path)
+		b.WriteString(hunks)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,102 @@
+package patch
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/brads3290/cclogviewer/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// changedFile returns the history of a file changed by a single successful call
+func changedFile(path, cwd, initial, final string, initialKnown bool) *models.FileHistory {
+	return &models.FileHistory{
+		Path:           path,
+		InitialContent: initial,
+		InitialKnown:   initialKnown,
+		Operations: []models.FileOperation{
+			{Tool: "Edit", Succeeded: true, CWD: cwd, Content: final, ContentKnown: true},
+		},
+	}
+}
+
+func TestWrite(t *testing.T) {
+	created := changedFile("/repo/src/new.go", "/repo", "", "package src\n", true)
+	created.Created = true
+	created.Operations[0].Tool = "Write"
+
+	files := []*models.FileHistory{
+		changedFile("/repo/main.go", "/repo", "a\nb\nc\n", "a\nB\nc\n", true),
+		created,
+		changedFile("/repo/unknown.go", "/repo", "", "x\n", false),
+		changedFile("/elsewhere/x.go", "/repo", "a\n", "b\n", true),
+		{Path: "/repo/read.go", Operations: []models.FileOperation{{Tool: "Read", Succeeded: true}}},
+	}
+
+	var out strings.Builder
+	skipped, err := Write(&out, files)
+	require.NoError(t, err)
+
+	assert.Equal(t, "diff --git a/main.go b/main.go\n--- a/main.go\n+++ b/main.go\n"+
+		"@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n"+
+		"diff --git a/src/new.go b/src/new.go\nnew file mode 100644\n--- /dev/null\n+++ b/src/new.go\n"+
+		"@@ -0,0 +1 @@\n+package src\n", out.String())
+
+	require.Len(t, skipped, 2)
+	assert.Equal(t, "/repo/unknown.go", skipped[0].Path)
+	assert.Contains(t, skipped[0].Reason, "unknown")
+	assert.Equal(t, "/elsewhere/x.go", skipped[1].Path)
+	assert.Contains(t, skipped[1].Reason, "outside the working directory")
+}
+
+func TestWrite_DifferentWorkingDirectories(t *testing.T) {
+	file := changedFile("/repo/sub/a.go", "/repo", "a\n", "b\n", true)
+	file.Operations = append(file.Operations,
+		models.FileOperation{Tool: "Edit", Succeeded: true, CWD: "/repo/sub", Content: "c\n", ContentKnown: true})
+
+	var out strings.Builder
+	skipped, err := Write(&out, []*models.FileHistory{file})
+	require.NoError(t, err)
+
+	assert.Empty(t, out.String())
+	require.Len(t, skipped, 1)
+	assert.Contains(t, skipped[0].Reason, "different working directories")
+}
+
+func TestWrite_GitApply(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte("a\nb\nc\nd\ne\nf\ng\nh\ni\nj\n"), 0644))
+	files := []*models.FileHistory{
+		changedFile(filepath.Join(dir, "main.go"), dir, "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\n", "a\nB\nc\nd\ne\nf\ng\nh\ni\nj", true),
+		changedFile(filepath.Join(dir, "new.txt"), dir, "", "hello\n", true),
+	}
+	files[1].Created = true
+
+	var out strings.Builder
+	_, err := Write(&out, files)
+	require.NoError(t, err)
+
+	patchFile := filepath.Join(t.TempDir(), "session.patch")
+	require.NoError(t, os.WriteFile(patchFile, []byte(out.String()), 0644))
+
+	cmd := exec.Command("git", "apply", patchFile)
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(output))
+
+	content, err := os.ReadFile(filepath.Join(dir, "main.go"))
+	require.NoError(t, err)
+	assert.Equal(t, "a\nB\nc\nd\ne\nf\ng\nh\ni\nj", string(content))
+
+	content, err = os.ReadFile(filepath.Join(dir, "new.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "hello\n", string(content))
+}
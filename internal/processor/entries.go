@@ -1,10 +1,10 @@
 package processor
 
 import (
-	"encoding/json"
 	"github.com/brads3290/cclogviewer/internal/constants"
+	"github.com/brads3290/cclogviewer/internal/debug"
 	"github.com/brads3290/cclogviewer/internal/models"
-	"github.com/brads3290/cclogviewer/internal/utils"
+	"log"
 	"strings"
 	"time"
 )
@@ -17,11 +17,18 @@ func ProcessEntries(entries []models.LogEntry) []*models.ProcessedEntry {
 	// Phase 1: Process all entries
 	processAllEntries(entries, state, entryMap)
 
+	return finishProcessing(state, entryMap).Entries
+}
+
+// finishProcessing runs every phase that needs the complete set of processed entries.
+func finishProcessing(state *ProcessingState, entryMap map[string]*models.ProcessedEntry) *models.Session {
 	// Phase 2: Match tool calls with results
 	matchToolCallsWithResults(state.Entries)
+	formatToolOutputs(state.Entries)
+	files := trackFileContents(state.Entries)
 
 	// Phase 3: Process sidechains
-	processSidechainConversations(state, entries, entryMap)
+	processSidechainConversations(state, entryMap)
 
 	// Phase 4-7: Post-processing
 	rootEntries := getRootEntries(state)
@@ -29,8 +36,14 @@ func ProcessEntries(entries []models.LogEntry) []*models.ProcessedEntry {
 	checkAllMissingResults(rootEntries)
 	linkAllCommandOutputs(rootEntries)
 	buildFinalHierarchy(rootEntries)
+	title := attachSummaries(state.Summaries, rootEntries, state, entryMap)
 
-	return rootEntries
+	return &models.Session{
+		Title:     title,
+		Summaries: state.Summaries,
+		Entries:   rootEntries,
+		Files:     files,
+	}
 }
 
 // checkMissingToolResults recursively checks for missing tool results and sidechains
@@ -55,10 +68,7 @@ func checkMissingToolResults(entry *models.ProcessedEntry) {
 		}
 	}
 
-	// Recursively check children
-	for _, child := range entry.Children {
-		checkMissingToolResults(child)
-	}
+	// Children need no separate walk, TaskEntries already lists every sidechain entry
 }
 
 // calculateTokensForEntry recursively aggregates tokens across nested tool calls.
@@ -87,6 +97,7 @@ func processEntry(entry models.LogEntry) *models.ProcessedEntry {
 	processed := &models.ProcessedEntry{
 		UUID:         entry.UUID,
 		IsSidechain:  entry.IsSidechain,
+		AgentID:      entry.AgentID,
 		Type:         entry.Type,
 		Timestamp:    formatTimestamp(entry.Timestamp),
 		RawTimestamp: entry.Timestamp,
@@ -96,66 +107,15 @@ func processEntry(entry models.LogEntry) *models.ProcessedEntry {
 		processed.ParentUUID = *entry.ParentUUID
 	}
 
-	// Process the message content
-	var msg map[string]interface{}
-	if err := json.Unmarshal(entry.Message, &msg); err == nil {
-		// Process message using handlers
-		if err := processMessage(processed, msg, entry); err != nil {
-			// Log error but continue processing
-		}
-
-		// Process token counts
-		tokenProcessor := NewTokenProcessor()
-		tokenProcessor.ProcessTokens(processed, msg)
-	} else {
-		// If we can't parse the message, estimate tokens from content
-		processed.TokenCount = EstimateTokens(string(processed.Content))
-	}
-
-	return processed
-}
-
-func collectSidechainEntries(root *models.ProcessedEntry, entryMap map[string]*models.ProcessedEntry) []*models.ProcessedEntry {
-	var result []*models.ProcessedEntry
-
-	// First, collect all tool results that are attached to tool calls
-	attachedToolResults := make(map[string]bool)
-	for _, entry := range entryMap {
-		if entry.IsSidechain {
-			for _, toolCall := range entry.ToolCalls {
-				if toolCall.Result != nil {
-					attachedToolResults[toolCall.Result.UUID] = true
-				}
-			}
-		}
+	handler, ok := entryHandlers[entry.Type]
+	if !ok {
+		handler = handleUnknownEntry
 	}
-
-	// Build the sidechain tree structure
-	var buildTree func(entry *models.ProcessedEntry, skipEntry bool)
-	buildTree = func(entry *models.ProcessedEntry, skipEntry bool) {
-		// Add to result only if we're not skipping this entry
-		if !skipEntry {
-			result = append(result, entry)
-		}
-
-		// Find and add children
-		for _, e := range entryMap {
-			if e.ParentUUID == entry.UUID && e.IsSidechain {
-				entry.Children = append(entry.Children, e)
-			}
-		}
-
-		// Recursively process children
-		for _, child := range entry.Children {
-			// Skip tool results that have been attached to tool calls when adding to result,
-			// but still process their children
-			shouldSkip := child.IsToolResult && attachedToolResults[child.UUID]
-			buildTree(child, shouldSkip)
-		}
+	if err := handler(processed, entry); err != nil && debug.Enabled {
+		log.Printf("Error processing %s entry %s: %v", entry.Type, entry.UUID, err)
 	}
 
-	buildTree(root, false)
-	return result
+	return processed
 }
 
 func formatTimestamp(ts string) string {
@@ -166,106 +126,12 @@ func formatTimestamp(ts string) string {
 	return t.Format("15:04:05")
 }
 
-func isToolResult(msg map[string]interface{}) bool {
-	if content, ok := msg["content"].([]interface{}); ok && len(content) > 0 {
-		if toolResult, ok := content[0].(map[string]interface{}); ok {
-			return utils.ExtractString(toolResult, "type") == constants.TypeToolResult
-		}
-	}
-	return false
-}
-
-
-
 // extractContent extracts text content from a ProcessedEntry
 func extractContent(entry *models.ProcessedEntry) string {
 	// Content is now stored as raw text, no HTML processing needed
 	return strings.TrimSpace(entry.Content)
 }
 
-
-// getFirstUserMessage finds the first user message in a sidechain conversation
-func getFirstUserMessage(root *models.ProcessedEntry, entryMap map[string]*models.ProcessedEntry) string {
-	// First check if root itself is a user message
-	if root.Role == constants.RoleUser {
-		return extractContent(root)
-	}
-
-	// Otherwise, look for the first user message in the tree
-	var findFirstUser func(entry *models.ProcessedEntry) string
-	findFirstUser = func(entry *models.ProcessedEntry) string {
-		// Check children first (in order)
-		for _, child := range entry.Children {
-			if child.Role == constants.RoleUser {
-				return extractContent(child)
-			}
-		}
-
-		// Then recursively check children's children
-		for _, child := range entry.Children {
-			if result := findFirstUser(child); result != "" {
-				return result
-			}
-		}
-
-		// Also check entries that have this as parent
-		for _, e := range entryMap {
-			if e.ParentUUID == entry.UUID && e.IsSidechain && e.Role == constants.RoleUser {
-				return extractContent(e)
-			}
-		}
-
-		return ""
-	}
-
-	return findFirstUser(root)
-}
-
-// getLastAssistantMessage finds the last assistant message in a sidechain conversation
-func getLastAssistantMessage(root *models.ProcessedEntry, entryMap map[string]*models.ProcessedEntry) string {
-	var lastAssistantContent string
-	var lastAssistantTime time.Time
-
-	var findLastAssistant func(entry *models.ProcessedEntry)
-	findLastAssistant = func(entry *models.ProcessedEntry) {
-		// Check if this is an assistant message
-		if entry.Role == constants.RoleAssistant && !entry.IsToolResult {
-			// Parse timestamp
-			if t, err := time.Parse(time.RFC3339, entry.RawTimestamp); err == nil {
-				if lastAssistantContent == "" || t.After(lastAssistantTime) {
-					lastAssistantContent = extractContent(entry)
-					lastAssistantTime = t
-				}
-			}
-		}
-
-		// Check children
-		for _, child := range entry.Children {
-			findLastAssistant(child)
-		}
-
-		// Check entries that have this as parent
-		for _, e := range entryMap {
-			if e.ParentUUID == entry.UUID && e.IsSidechain {
-				// Avoid infinite recursion by checking if already in children
-				found := false
-				for _, child := range entry.Children {
-					if child.UUID == e.UUID {
-						found = true
-						break
-					}
-				}
-				if !found {
-					findLastAssistant(e)
-				}
-			}
-		}
-	}
-
-	findLastAssistant(root)
-	return lastAssistantContent
-}
-
 // normalizeText normalizes text for comparison by removing extra whitespace and newlines
 func normalizeText(text string) string {
 	// Replace all newlines with spaces
@@ -316,6 +182,7 @@ func linkCommandOutputs(entries []*models.ProcessedEntry) {
 			current.CommandOutput = extractXMLContent(next.Content, constants.TagCommandStdout)
 			// Mark the next entry for removal
 			next.Content = ""
+			next.Blocks = nil
 		}
 	}
 }
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/brads3290/cclogviewer/internal/models"
+	"github.com/brads3290/cclogviewer/internal/processor/tools"
 	"github.com/brads3290/cclogviewer/internal/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -178,4 +179,75 @@ func TestProcessEntry_Simple(t *testing.T) {
 	assert.Equal(t, entry.UUID, result.UUID)
 	assert.Equal(t, entry.Type, result.Type)
 	assert.NotEmpty(t, result.Timestamp)
-}
\ No newline at end of file
+}
+
+func TestProcessAssistantMessage_PreservesBlockOrder(t *testing.T) {
+	msg := map[string]interface{}{
+		"role": "assistant",
+		"content": []interface{}{
+			map[string]interface{}{"type": "text", "text": "First I'll read the file."},
+			map[string]interface{}{"type": "tool_use", "id": "tool-1", "name": "Read", "input": map[string]interface{}{"file_path": "/a.go"}},
+			map[string]interface{}{"type": "text", "text": "Now I'll edit it."},
+			map[string]interface{}{"type": "tool_use", "id": "tool-2", "name": "Edit", "input": map[string]interface{}{
+				"file_path": "/a.go", "old_string": "a", "new_string": "b",
+			}},
+		},
+	}
+
+	content, toolCalls, blocks := ProcessAssistantMessage(msg, "/work")
+
+	assert.Equal(t, "First I'll read the file.Now I'll edit it.", content)
+	require.Len(t, toolCalls, 2)
+	require.Len(t, blocks, 4)
+
+	assert.Equal(t, "text", blocks[0].Type)
+	assert.Equal(t, "First I'll read the file.", blocks[0].Text)
+	assert.Equal(t, "tool_use", blocks[1].Type)
+	assert.Same(t, &toolCalls[0], blocks[1].ToolCall)
+	assert.Equal(t, "Now I'll edit it.", blocks[2].Text)
+	assert.Same(t, &toolCalls[1], blocks[3].ToolCall)
+	assert.Equal(t, "/work", blocks[3].ToolCall.CWD)
+}
+
+func TestProcessEntry_ThinkingBlocks(t *testing.T) {
+	entry := models.LogEntry{
+		UUID:      "msg-1",
+		Type:      "assistant",
+		Timestamp: "2024-01-01T10:00:00Z",
+		Message: []byte(`{"role":"assistant","usage":{"input_tokens":10},"content":[` +
+			`{"type":"thinking","thinking":"The user wants a list of files","signature":"abc"},` +
+			`{"type":"redacted_thinking","data":"encrypted"},` +
+			`{"type":"text","text":"Here are the files"}]}`),
+	}
+
+	result := processEntry(entry)
+
+	require.Len(t, result.Blocks, 3)
+	assert.Equal(t, "thinking", result.Blocks[0].Type)
+	assert.Equal(t, "The user wants a list of files", result.Blocks[0].Text)
+	assert.Equal(t, "redacted_thinking", result.Blocks[1].Type)
+	assert.Equal(t, "Here are the files", result.Content)
+
+	assert.Equal(t, EstimateTokens("The user wants a list of files"), result.ThinkingTokens)
+	assert.Equal(t, EstimateTokens("Here are the files")+result.ThinkingTokens, result.OutputTokens)
+}
+
+func TestProcessToolCall_InputTemplateError(t *testing.T) {
+	formatter, err := tools.NewDeclarativeFormatter(tools.DeclarativeSpec{
+		Tool:          "mcp__jira__get_issue",
+		InputTemplate: `{{index .Input.keys 3}}`,
+	})
+	require.NoError(t, err)
+	registry := tools.NewFormatterRegistry()
+	registry.Register(formatter)
+	tp := &ToolProcessor{registry: registry}
+
+	toolCall := &models.ToolCall{
+		Name:     "mcp__jira__get_issue",
+		RawInput: map[string]interface{}{"keys": []interface{}{"APP-1"}},
+	}
+	tp.ProcessToolCall(toolCall)
+
+	assert.Contains(t, string(toolCall.Input), `class="json-tree"`, "a failing template should fall back to the JSON tree")
+	assert.Contains(t, string(toolCall.Input), "APP-1")
+}
@@ -0,0 +1,151 @@
+package processor
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+
+	"github.com/brads3290/cclogviewer/internal/constants"
+	"github.com/brads3290/cclogviewer/internal/models"
+)
+
+// EntryHandler processes log entries of a specific type.
+type EntryHandler func(*models.ProcessedEntry, models.LogEntry) error
+
+// entryHandlers maps entry types to their handlers. Entries of other types are
+// handled by handleUnknownEntry.
+var entryHandlers = map[string]EntryHandler{
+	constants.TypeUser:                     handleMessageEntry,
+	constants.TypeAssistant:                handleMessageEntry,
+	constants.TypeMessage:                  handleMessageEntry,
+	constants.EntryTypeSystem:              handleSystemEntry,
+	constants.EntryTypeFileHistorySnapshot: handleFileHistorySnapshot,
+}
+
+// RegisterEntryHandler sets the handler for log entries of the given type
+func RegisterEntryHandler(entryType string, handler EntryHandler) {
+	entryHandlers[entryType] = handler
+}
+
+// handleMessageEntry processes user and assistant conversation messages
+func handleMessageEntry(processed *models.ProcessedEntry, entry models.LogEntry) error {
+	var msg map[string]interface{}
+	if err := json.Unmarshal(entry.Message, &msg); err != nil {
+		// If we can't parse the message, estimate tokens from content
+		processed.TokenCount = EstimateTokens(processed.Content)
+		return nil
+	}
+
+	err := processMessage(processed, msg, entry)
+
+	// Process token counts even if the message handler failed
+	tokenProcessor := NewTokenProcessor()
+	tokenProcessor.ProcessTokens(processed, msg)
+
+	return err
+}
+
+// systemEntry is the JSON shape of system entries
+type systemEntry struct {
+	Subtype string          `json:"subtype"`
+	Level   string          `json:"level"`
+	Content json.RawMessage `json:"content"`
+}
+
+// handleSystemEntry processes system entries such as hook output, compaction notices and API errors
+func handleSystemEntry(processed *models.ProcessedEntry, entry models.LogEntry) error {
+	var raw systemEntry
+	if err := json.Unmarshal(entry.Raw, &raw); err != nil {
+		return handleUnknownEntry(processed, entry)
+	}
+
+	processed.Role = constants.RoleSystem
+	processed.System = &models.SystemInfo{
+		Subtype: raw.Subtype,
+		Level:   raw.Level,
+		Content: rawText(raw.Content),
+	}
+	processed.IsError = raw.Level == constants.SystemLevelError
+
+	return nil
+}
+
+// fileHistorySnapshotEntry is the JSON shape of file-history-snapshot entries
+type fileHistorySnapshotEntry struct {
+	MessageID        string `json:"messageId"`
+	IsSnapshotUpdate bool   `json:"isSnapshotUpdate"`
+	Snapshot         struct {
+		TrackedFileBackups map[string]struct {
+			BackupFileName string `json:"backupFileName"`
+			Version        int    `json:"version"`
+			BackupTime     string `json:"backupTime"`
+		} `json:"trackedFileBackups"`
+		Timestamp string `json:"timestamp"`
+	} `json:"snapshot"`
+}
+
+// handleFileHistorySnapshot processes the checkpoints Claude Code takes before changing files
+func handleFileHistorySnapshot(processed *models.ProcessedEntry, entry models.LogEntry) error {
+	var raw fileHistorySnapshotEntry
+	if err := json.Unmarshal(entry.Raw, &raw); err != nil {
+		return handleUnknownEntry(processed, entry)
+	}
+
+	snapshot := &models.FileSnapshot{
+		MessageID: raw.MessageID,
+		IsUpdate:  raw.IsSnapshotUpdate,
+	}
+	for path, backup := range raw.Snapshot.TrackedFileBackups {
+		snapshot.Files = append(snapshot.Files, models.TrackedFile{
+			Path:           path,
+			BackupFileName: backup.BackupFileName,
+			Version:        backup.Version,
+			BackupTime:     backup.BackupTime,
+		})
+	}
+	sort.Slice(snapshot.Files, func(i, j int) bool {
+		return snapshot.Files[i].Path < snapshot.Files[j].Path
+	})
+
+	// Snapshots carry their timestamp inside the snapshot object
+	if processed.RawTimestamp == "" {
+		processed.RawTimestamp = raw.Snapshot.Timestamp
+		processed.Timestamp = formatTimestamp(raw.Snapshot.Timestamp)
+	}
+	processed.Snapshot = snapshot
+
+	return nil
+}
+
+// handleUnknownEntry keeps the raw JSON of entries without a dedicated handler so they can still be shown
+func handleUnknownEntry(processed *models.ProcessedEntry, entry models.LogEntry) error {
+	raw := []byte(entry.Raw)
+	if len(raw) == 0 {
+		var err error
+		if raw, err = json.Marshal(entry); err != nil {
+			return err
+		}
+	}
+
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, raw, "", "  "); err != nil {
+		processed.RawJSON = string(raw)
+		return err
+	}
+	processed.RawJSON = indented.String()
+
+	return nil
+}
+
+// rawText returns a JSON string value as text, or the JSON itself for other values
+func rawText(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return ""
+	}
+
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return text
+	}
+	return string(raw)
+}
@@ -0,0 +1,70 @@
+package processor
+
+import (
+	"testing"
+
+	"github.com/brads3290/cclogviewer/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcessEntries_NonMessageEntries(t *testing.T) {
+	entries := []models.LogEntry{
+		{
+			UUID:      "sys-1",
+			Type:      "system",
+			Timestamp: "2024-01-01T10:00:00Z",
+			Raw:       []byte(`{"type":"system","uuid":"sys-1","subtype":"informational","level":"warning","content":"PostToolUse hook failed"}`),
+		},
+		{
+			Type: "file-history-snapshot",
+			Raw:  []byte(`{"type":"file-history-snapshot","messageId":"msg-1","isSnapshotUpdate":false,"snapshot":{"messageId":"msg-1","timestamp":"2024-01-01T10:00:01Z","trackedFileBackups":{"b.go":{"backupFileName":null,"version":1,"backupTime":"2024-01-01T10:00:01Z"},"a.go":{"backupFileName":"abc@v2","version":2,"backupTime":"2024-01-01T10:00:01Z"}}}}`),
+		},
+		{
+			UUID: "q-1",
+			Type: "queue-operation",
+			Raw:  []byte(`{"type":"queue-operation","uuid":"q-1","operation":"enqueue"}`),
+		},
+	}
+
+	processed := ProcessEntries(entries)
+	require.Len(t, processed, 3)
+
+	system := processed[0].System
+	require.NotNil(t, system)
+	assert.Equal(t, "informational", system.Subtype)
+	assert.Equal(t, "warning", system.Level)
+	assert.Equal(t, "PostToolUse hook failed", system.Content)
+
+	snapshot := processed[1].Snapshot
+	require.NotNil(t, snapshot)
+	assert.Equal(t, "msg-1", snapshot.MessageID)
+	require.Len(t, snapshot.Files, 2)
+	assert.Equal(t, "a.go", snapshot.Files[0].Path)
+	assert.Equal(t, "abc@v2", snapshot.Files[0].BackupFileName)
+	assert.Equal(t, "", snapshot.Files[1].BackupFileName)
+	assert.Equal(t, "2024-01-01T10:00:01Z", processed[1].RawTimestamp)
+
+	assert.Contains(t, processed[2].RawJSON, `"operation": "enqueue"`)
+}
+
+func TestRegisterEntryHandler(t *testing.T) {
+	original, existed := entryHandlers["custom"]
+	defer func() {
+		if existed {
+			entryHandlers["custom"] = original
+		} else {
+			delete(entryHandlers, "custom")
+		}
+	}()
+
+	RegisterEntryHandler("custom", func(processed *models.ProcessedEntry, entry models.LogEntry) error {
+		processed.Content = "handled"
+		return nil
+	})
+
+	processed := ProcessEntries([]models.LogEntry{{UUID: "c-1", Type: "custom"}})
+	require.Len(t, processed, 1)
+	assert.Equal(t, "handled", processed[0].Content)
+	assert.Empty(t, processed[0].RawJSON)
+}
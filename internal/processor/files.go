@@ -0,0 +1,350 @@
+package processor
+
+import (
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/brads3290/cclogviewer/internal/constants"
+	"github.com/brads3290/cclogviewer/internal/models"
+	"github.com/brads3290/cclogviewer/internal/processor/tools/diff"
+	"github.com/brads3290/cclogviewer/internal/utils"
+)
+
+// readLinePattern matches a line of a Read result: the line number, then "→" or a tab
+var readLinePattern = regexp.MustCompile(`^\s*(\d+)(?:→|\t)(.*)$`)
+
+// fileContent is the part of a file known at some point of a session.
+type fileContent struct {
+	startLine int      // File line number of lines[0]
+	lines     []string // Known lines, in order
+	complete  bool     // True if lines is the whole file
+}
+
+// text returns the known lines joined into a single string
+func (c *fileContent) text() string {
+	return strings.Join(c.lines, "\n")
+}
+
+// FileTracker follows the latest known content of each file in a session, as seen
+// through Read, Write, Edit and MultiEdit tool calls, and records the history of each file.
+type FileTracker struct {
+	files     map[string]*fileContent
+	histories map[string]*models.FileHistory
+	paths     []string // Files in the order they were first used
+}
+
+// NewFileTracker creates an empty file tracker
+func NewFileTracker() *FileTracker {
+	return &FileTracker{
+		files:     make(map[string]*fileContent),
+		histories: make(map[string]*models.FileHistory),
+	}
+}
+
+// Content returns the latest known content of a file and whether the whole file is known
+func (t *FileTracker) Content(path string) (string, bool) {
+	content := t.files[filepath.Clean(path)]
+	if content == nil {
+		return "", false
+	}
+	return content.text(), content.complete
+}
+
+// Observe updates the tracked files with a tool call of an entry. For Edit and MultiEdit
+// calls it first records the file line each edit starts at in the tool call's EditLines.
+func (t *FileTracker) Observe(entry *models.ProcessedEntry, toolCall *models.ToolCall) {
+	input, ok := toolCall.RawInput.(map[string]interface{})
+	if !ok {
+		return
+	}
+	path := utils.ExtractString(input, "file_path")
+	if path == "" {
+		return
+	}
+	path = filepath.Clean(path)
+
+	// Calls that failed didn't change the file
+	succeeded := toolCall.Result != nil && !toolCall.Result.IsError
+
+	history := t.history(path)
+	if toolCall.Name == constants.ToolNameEdit || toolCall.Name == constants.ToolNameMultiEdit {
+		t.observeOriginal(path, toolCall.Result)
+	}
+	if toolCall.Name != constants.ToolNameRead && succeeded && !history.Changed() {
+		t.observeInitial(history, toolCall)
+	}
+
+	switch toolCall.Name {
+	case constants.ToolNameRead:
+		if succeeded {
+			t.observeRead(path, input, toolCall.Result)
+		}
+	case constants.ToolNameWrite:
+		if succeeded {
+			t.files[path] = newFileContent(utils.ExtractString(input, "content"))
+		}
+	case constants.ToolNameEdit:
+		toolCall.EditLines = []int{t.applyEdit(path, input, succeeded)}
+	case constants.ToolNameMultiEdit:
+		toolCall.EditLines = nil
+		for _, edit := range utils.ExtractSlice(input, "edits") {
+			if editInput, ok := edit.(map[string]interface{}); ok {
+				toolCall.EditLines = append(toolCall.EditLines, t.applyEdit(path, editInput, succeeded))
+			}
+		}
+	default:
+		return
+	}
+
+	op := models.FileOperation{
+		ToolCallID:  toolCall.ID,
+		Tool:        toolCall.Name,
+		Timestamp:   entry.Timestamp,
+		CWD:         toolCall.CWD,
+		Succeeded:   succeeded,
+		IsSidechain: entry.IsSidechain,
+	}
+	op.Content, op.ContentKnown = t.Content(path)
+	if !op.ContentKnown {
+		op.Content = ""
+	}
+	if succeeded {
+		switch toolCall.Name {
+		case constants.ToolNameWrite:
+			op.Written = utils.ExtractString(input, "content")
+		case constants.ToolNameEdit:
+			op.Edits = []models.FileEdit{fileEdit(input)}
+		case constants.ToolNameMultiEdit:
+			for _, edit := range utils.ExtractSlice(input, "edits") {
+				if editInput, ok := edit.(map[string]interface{}); ok {
+					op.Edits = append(op.Edits, fileEdit(editInput))
+				}
+			}
+		}
+	}
+	// Calls that leave the file as it was, like Reads, share the content of the call before
+	if previous := history.Final(); previous != nil && previous.ContentKnown && op.ContentKnown && previous.Content == op.Content {
+		op.Content = previous.Content
+	}
+	history.Operations = append(history.Operations, op)
+}
+
+// Histories returns the history of every file the session used, in the order the files were
+// first used. The cumulative diff of each changed file is computed here.
+func (t *FileTracker) Histories() []*models.FileHistory {
+	histories := make([]*models.FileHistory, 0, len(t.paths))
+	for _, path := range t.paths {
+		history := t.histories[path]
+		if len(history.Operations) == 0 {
+			continue
+		}
+
+		final := history.Final()
+		if history.Changed() && history.InitialKnown && final.ContentKnown {
+			diffLines := diff.ComputeFileDiff(history.InitialContent, final.Content)
+			history.CumulativeDiff = diff.FormatDiffHTML(diff.HighlightChanges(diffLines))
+		}
+		histories = append(histories, history)
+	}
+	return histories
+}
+
+// history returns the history of a file, creating it on first use
+func (t *FileTracker) history(path string) *models.FileHistory {
+	history := t.histories[path]
+	if history == nil {
+		history = &models.FileHistory{Path: path}
+		t.histories[path] = history
+		t.paths = append(t.paths, path)
+	}
+	return history
+}
+
+// observeInitial records the file content from before the first change of a file
+func (t *FileTracker) observeInitial(history *models.FileHistory, toolCall *models.ToolCall) {
+	if toolCall.Name == constants.ToolNameWrite {
+		if payload, ok := toolCall.Result.ToolUseResult.(map[string]interface{}); ok &&
+			utils.ExtractString(payload, "type") == constants.WriteResultTypeCreate {
+			history.Created = true
+			history.InitialContent, history.InitialKnown = "", true
+			return
+		}
+	}
+
+	history.InitialContent, history.InitialKnown = t.Content(history.Path)
+	if !history.InitialKnown {
+		history.InitialContent = ""
+	}
+}
+
+// observeRead records the content returned by a Read call
+func (t *FileTracker) observeRead(path string, input map[string]interface{}, result *models.ProcessedEntry) {
+	// The structured result holds the content without line numbers
+	if payload, ok := result.ToolUseResult.(map[string]interface{}); ok {
+		if file := utils.ExtractMap(payload, "file"); file != nil {
+			if content, ok := file["content"].(string); ok {
+				startLine := utils.ExtractInt(file, "startLine")
+				if startLine < 1 {
+					startLine = 1
+				}
+				numLines := utils.ExtractInt(file, "numLines")
+				t.setRead(path, &fileContent{
+					startLine: startLine,
+					lines:     strings.Split(content, "\n"),
+					complete:  startLine == 1 && numLines == utils.ExtractInt(file, "totalLines"),
+				})
+				return
+			}
+		}
+	}
+
+	content := parseReadResult(result.Content)
+	if content == nil {
+		return
+	}
+
+	// A default Read returns the whole file unless it hit the line limit
+	_, hasOffset := input["offset"]
+	_, hasLimit := input["limit"]
+	content.complete = !hasOffset && !hasLimit && content.startLine == 1 &&
+		len(content.lines) < constants.ReadDefaultLineLimit
+	t.setRead(path, content)
+}
+
+// setRead records file content returned by a Read call. Content already known completely,
+// like that of a Write or of the original file of an Edit, is kept, since Read results
+// don't show whether a file ends with a newline.
+func (t *FileTracker) setRead(path string, content *fileContent) {
+	if known := t.files[path]; known != nil && known.complete {
+		return
+	}
+	t.files[path] = content
+}
+
+// observeOriginal records the file content an Edit or MultiEdit result reports from before the edit
+func (t *FileTracker) observeOriginal(path string, result *models.ProcessedEntry) {
+	if result == nil {
+		return
+	}
+	payload, ok := result.ToolUseResult.(map[string]interface{})
+	if !ok {
+		return
+	}
+	if original, ok := payload["originalFile"].(string); ok {
+		t.files[path] = newFileContent(original)
+	}
+}
+
+// applyEdit returns the file line an edit starts at, or 0 if it can't be found, and applies
+// the edit to the tracked content if it succeeded
+func (t *FileTracker) applyEdit(path string, edit map[string]interface{}, succeeded bool) int {
+	content := t.files[path]
+	oldString := utils.ExtractString(edit, "old_string")
+	if content == nil || oldString == "" {
+		return 0
+	}
+
+	text := content.text()
+	idx := strings.Index(text, oldString)
+	if idx < 0 {
+		// The tracked content is out of date, so later edits can't be placed either
+		if succeeded {
+			delete(t.files, path)
+		}
+		return 0
+	}
+	line := content.startLine + strings.Count(text[:idx], "\n")
+
+	if succeeded {
+		newString := utils.ExtractString(edit, "new_string")
+		if utils.ExtractBool(edit, "replace_all") {
+			text = strings.ReplaceAll(text, oldString, newString)
+		} else {
+			text = text[:idx] + newString + text[idx+len(oldString):]
+		}
+		content.lines = strings.Split(text, "\n")
+	}
+
+	return line
+}
+
+// fileEdit returns the replacement of an Edit input or of an edit of a MultiEdit input
+func fileEdit(edit map[string]interface{}) models.FileEdit {
+	return models.FileEdit{
+		OldString:  utils.ExtractString(edit, "old_string"),
+		NewString:  utils.ExtractString(edit, "new_string"),
+		ReplaceAll: utils.ExtractBool(edit, "replace_all"),
+	}
+}
+
+// newFileContent creates the tracked content of a completely known file
+func newFileContent(text string) *fileContent {
+	return &fileContent{startLine: 1, lines: strings.Split(text, "\n"), complete: true}
+}
+
+// parseReadResult extracts the file lines from the text of a Read result. It returns nil
+// if the result holds no numbered lines.
+func parseReadResult(result string) *fileContent {
+	var content *fileContent
+
+	for _, line := range strings.Split(result, "\n") {
+		match := readLinePattern.FindStringSubmatch(line)
+		if match == nil {
+			// Notes such as system reminders follow the file content
+			if content != nil {
+				break
+			}
+			continue
+		}
+
+		lineNum, _ := strconv.Atoi(match[1])
+		if content == nil {
+			content = &fileContent{startLine: lineNum}
+		} else if lineNum != content.startLine+len(content.lines) {
+			break
+		}
+		content.lines = append(content.lines, match[2])
+	}
+
+	return content
+}
+
+// trackFileContents places Edit and MultiEdit calls at their file lines by replaying every
+// file tool call of the session, including those of subagents, in time order. It returns
+// the history of each file.
+func trackFileContents(entries []*models.ProcessedEntry) []*models.FileHistory {
+	// Timestamps are compared as times, since they may differ in precision or offset. Entries
+	// without a valid timestamp keep the time of the entry before them.
+	times := make(map[*models.ProcessedEntry]time.Time, len(entries))
+	var last time.Time
+	for _, entry := range entries {
+		if t, err := time.Parse(time.RFC3339, entry.RawTimestamp); err == nil {
+			last = t
+		}
+		times[entry] = last
+	}
+
+	ordered := make([]*models.ProcessedEntry, len(entries))
+	copy(ordered, entries)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return times[ordered[i]].Before(times[ordered[j]])
+	})
+
+	tracker := NewFileTracker()
+	for _, entry := range ordered {
+		for i := range entry.ToolCalls {
+			toolCall := &entry.ToolCalls[i]
+			tracker.Observe(entry, toolCall)
+
+			if toolCall.EditLines != nil {
+				GetToolProcessor().FormatEditAtLines(toolCall)
+			}
+		}
+	}
+
+	return tracker.Histories()
+}
@@ -0,0 +1,237 @@
+package processor
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/brads3290/cclogviewer/internal/constants"
+	"github.com/brads3290/cclogviewer/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fileToolEntries returns a tool call of the given tool and its result
+func fileToolEntries(id, timestamp, name, input, result string, toolUseResult interface{}) []models.LogEntry {
+	return []models.LogEntry{
+		{
+			UUID:      id + "-call",
+			Type:      constants.TypeAssistant,
+			Timestamp: timestamp + "0Z",
+			Message:   []byte(`{"role":"assistant","content":[{"type":"tool_use","id":"` + id + `","name":"` + name + `","input":` + input + `}]}`),
+		},
+		{
+			UUID:          id + "-result",
+			Type:          constants.TypeUser,
+			Timestamp:     timestamp + "1Z",
+			Message:       []byte(`{"role":"user","content":[{"type":"tool_result","tool_use_id":"` + id + `","content":` + result + `}]}`),
+			ToolUseResult: toolUseResult,
+		},
+	}
+}
+
+// processFileTools processes the entries and returns the tool calls by ID
+func processFileTools(t *testing.T, groups ...[]models.LogEntry) map[string]*models.ToolCall {
+	var entries []models.LogEntry
+	for _, group := range groups {
+		entries = append(entries, group...)
+	}
+
+	toolCalls := make(map[string]*models.ToolCall)
+	for _, entry := range ProcessEntries(entries) {
+		for i := range entry.ToolCalls {
+			toolCalls[entry.ToolCalls[i].ID] = &entry.ToolCalls[i]
+		}
+	}
+	require.NotEmpty(t, toolCalls)
+	return toolCalls
+}
+
+func TestTrackFileContents_ReadThenEdit(t *testing.T) {
+	toolCalls := processFileTools(t,
+		fileToolEntries("read", "2024-01-01T10:00:0", constants.ToolNameRead,
+			`{"file_path":"/src/a.go"}`, `"     1→package a\n     2→\n     3→func A() {}"`, nil),
+		fileToolEntries("edit", "2024-01-01T10:00:1", constants.ToolNameEdit,
+			`{"file_path":"/src/a.go","old_string":"func A() {}","new_string":"func A() int {\n\treturn 1\n}"}`, `"ok"`, nil),
+		fileToolEntries("edit2", "2024-01-01T10:00:2", constants.ToolNameEdit,
+			`{"file_path":"/src/a.go","old_string":"\treturn 1","new_string":"\treturn 2"}`, `"ok"`, nil),
+	)
+
+	assert.Equal(t, []int{3}, toolCalls["edit"].EditLines)
+	assert.Equal(t, []int{4}, toolCalls["edit2"].EditLines)
+	assert.Contains(t, string(toolCalls["edit2"].Input), `<span class="line-number">  4</span>`)
+	assert.NotContains(t, string(toolCalls["edit2"].Input), "diff-location-unknown")
+}
+
+func TestTrackFileContents_WriteThenMultiEdit(t *testing.T) {
+	toolCalls := processFileTools(t,
+		fileToolEntries("write", "2024-01-01T10:00:0", constants.ToolNameWrite,
+			`{"file_path":"/src/b.txt","content":"one\ntwo\nthree\nfour"}`, `"written"`, nil),
+		fileToolEntries("multi", "2024-01-01T10:00:1", constants.ToolNameMultiEdit,
+			`{"file_path":"/src/b.txt","edits":[{"old_string":"two","new_string":"2"},{"old_string":"four","new_string":"4"},{"old_string":"missing","new_string":"x"}]}`,
+			`"edited"`, nil),
+	)
+
+	assert.Equal(t, []int{2, 4, 0}, toolCalls["multi"].EditLines)
+	assert.Equal(t, 1, strings.Count(string(toolCalls["multi"].Input), "diff-location-unknown"))
+}
+
+func TestTrackFileContents_OriginalFileFromResult(t *testing.T) {
+	toolCalls := processFileTools(t,
+		fileToolEntries("edit", "2024-01-01T10:00:0", constants.ToolNameEdit,
+			`{"file_path":"/src/c.txt","old_string":"c","new_string":"C"}`, `"ok"`,
+			map[string]interface{}{"originalFile": "a\nb\nc"}),
+	)
+
+	assert.Equal(t, []int{3}, toolCalls["edit"].EditLines)
+}
+
+func TestTrackFileContents_OrdersByTime(t *testing.T) {
+	read := fileToolEntries("read", "2024-01-01T10:00:0", constants.ToolNameRead,
+		`{"file_path":"/src/e.txt"}`, `"     1→a\n     2→b"`, nil)
+	edit := fileToolEntries("edit", "2024-01-01T10:00:0", constants.ToolNameEdit,
+		`{"file_path":"/src/e.txt","old_string":"b","new_string":"B"}`, `"ok"`, nil)
+	// Sorted as text, the fractional timestamps would come before the read
+	read[0].Timestamp, read[1].Timestamp = "2024-01-01T10:00:01Z", "2024-01-01T10:00:01.2Z"
+	edit[0].Timestamp, edit[1].Timestamp = "2024-01-01T10:00:01.5Z", "2024-01-01T12:00:01.7+02:00"
+
+	toolCalls := processFileTools(t, read, edit)
+
+	assert.Equal(t, []int{2}, toolCalls["edit"].EditLines)
+}
+
+func TestTrackFileContents_StructuredRead(t *testing.T) {
+	var entries []models.LogEntry
+	for _, group := range [][]models.LogEntry{
+		fileToolEntries("read", "2024-01-01T10:00:0", constants.ToolNameRead,
+			`{"file_path":"/src/f.txt"}`, `"     1→x\n     2→y"`,
+			map[string]interface{}{"file": map[string]interface{}{"content": "x\ny", "startLine": 1.0, "numLines": 2.0, "totalLines": 2.0}}),
+		fileToolEntries("edit", "2024-01-01T10:00:1", constants.ToolNameEdit,
+			`{"file_path":"/src/f.txt","old_string":"y","new_string":"z"}`, `"ok"`, nil),
+	} {
+		entries = append(entries, group...)
+	}
+
+	sp := NewStreamProcessor()
+	for _, entry := range entries {
+		sp.Add(entry)
+	}
+	files := sp.FinishSession().Files
+	require.Len(t, files, 1)
+
+	assert.Equal(t, "x\ny", files[0].InitialContent)
+	assert.Equal(t, "x\nz", files[0].Final().Content)
+}
+
+func TestTrackFileContents_ReadAfterWrite(t *testing.T) {
+	var entries []models.LogEntry
+	for _, group := range [][]models.LogEntry{
+		fileToolEntries("write", "2024-01-01T10:00:0", constants.ToolNameWrite,
+			`{"file_path":"/src/g.txt","content":"one\ntwo"}`, `"created"`,
+			map[string]interface{}{"type": "create"}),
+		fileToolEntries("read", "2024-01-01T10:00:1", constants.ToolNameRead,
+			`{"file_path":"/src/g.txt"}`, `"     1→one\n     2→two"`, nil),
+	} {
+		entries = append(entries, group...)
+	}
+
+	sp := NewStreamProcessor()
+	for _, entry := range entries {
+		sp.Add(entry)
+	}
+	files := sp.FinishSession().Files
+	require.Len(t, files, 1)
+
+	// The Read doesn't show whether the file ends with a newline, so the written content is kept
+	assert.Equal(t, "one\ntwo", files[0].Final().Content)
+}
+
+func TestTrackFileContents_UnknownFile(t *testing.T) {
+	toolCalls := processFileTools(t,
+		fileToolEntries("edit", "2024-01-01T10:00:0", constants.ToolNameEdit,
+			`{"file_path":"/src/d.txt","old_string":"c","new_string":"C"}`, `"ok"`, nil),
+	)
+
+	assert.Equal(t, []int{0}, toolCalls["edit"].EditLines)
+	assert.Contains(t, string(toolCalls["edit"].Input), "diff-location-unknown")
+}
+
+func TestFileTracker_FailedEditKeepsContent(t *testing.T) {
+	toolCalls := processFileTools(t,
+		fileToolEntries("write", "2024-01-01T10:00:0", constants.ToolNameWrite,
+			`{"file_path":"/src/e.txt","content":"x\ny"}`, `"written"`, nil),
+		fileToolEntries("edit", "2024-01-01T10:00:1", constants.ToolNameEdit,
+			`{"file_path":"/src/e.txt","old_string":"y","new_string":"z"}`, `"<tool_use_error>File has been modified</tool_use_error>"`, nil),
+	)
+
+	tracker := NewFileTracker()
+	entry := &models.ProcessedEntry{}
+	tracker.Observe(entry, toolCalls["write"])
+	toolCalls["edit"].Result.IsError = true
+	tracker.Observe(entry, toolCalls["edit"])
+
+	content, complete := tracker.Content("/src/e.txt")
+	assert.Equal(t, "x\ny", content)
+	assert.True(t, complete)
+}
+
+func TestParseReadResult(t *testing.T) {
+	content := parseReadResult("    10→a\n    11→b\n\n<system-reminder>note</system-reminder>")
+	require.NotNil(t, content)
+	assert.Equal(t, 10, content.startLine)
+	assert.Equal(t, []string{"a", "b"}, content.lines)
+
+	assert.Nil(t, parseReadResult("File does not exist."))
+}
+
+func TestTrackFileContents_Histories(t *testing.T) {
+	var entries []models.LogEntry
+	for _, group := range [][]models.LogEntry{
+		fileToolEntries("write", "2024-01-01T10:00:0", constants.ToolNameWrite,
+			`{"file_path":"/src/new.txt","content":"one\ntwo"}`, `"created"`,
+			map[string]interface{}{"type": "create"}),
+		fileToolEntries("read", "2024-01-01T10:00:1", constants.ToolNameRead,
+			`{"file_path":"/src/old.txt"}`, `"     1→x\n     2→y"`, nil),
+		fileToolEntries("edit", "2024-01-01T10:00:2", constants.ToolNameEdit,
+			`{"file_path":"/src/old.txt","old_string":"y","new_string":"z"}`, `"ok"`, nil),
+		fileToolEntries("edit2", "2024-01-01T10:00:3", constants.ToolNameEdit,
+			`{"file_path":"/src/new.txt","old_string":"two","new_string":"2"}`, `"ok"`, nil),
+		fileToolEntries("edit3", "2024-01-01T10:00:4", constants.ToolNameEdit,
+			`{"file_path":"/src/unknown.txt","old_string":"a","new_string":"b"}`, `"ok"`, nil),
+	} {
+		entries = append(entries, group...)
+	}
+
+	sp := NewStreamProcessor()
+	for _, entry := range entries {
+		sp.Add(entry)
+	}
+	files := sp.FinishSession().Files
+	require.Len(t, files, 3)
+
+	created := files[0]
+	assert.Equal(t, "/src/new.txt", created.Path)
+	assert.True(t, created.Created)
+	require.Len(t, created.Operations, 2)
+	assert.Equal(t, "one\ntwo", created.Operations[0].Written)
+	assert.Equal(t, []models.FileEdit{{OldString: "two", NewString: "2"}}, created.Operations[1].Edits)
+	assert.Equal(t, "one\n2", created.Final().Content)
+	assert.True(t, created.Operations[0].ContentKnown)
+	assert.Equal(t, "one\ntwo", created.Operations[0].Content, "Expected the content after each step")
+	assert.Contains(t, string(created.CumulativeDiff), "line-added")
+	assert.NotContains(t, string(created.CumulativeDiff), "line-removed")
+
+	changed := files[1]
+	assert.False(t, changed.Created)
+	assert.True(t, changed.InitialKnown)
+	assert.Equal(t, "x\ny", changed.InitialContent)
+	require.Len(t, changed.Operations, 2)
+	assert.Equal(t, constants.ToolNameRead, changed.Operations[0].Tool)
+	assert.Equal(t, "x\nz", changed.Final().Content)
+	assert.NotEmpty(t, changed.CumulativeDiff)
+
+	unknown := files[2]
+	assert.True(t, unknown.Changed())
+	assert.False(t, unknown.InitialKnown)
+	assert.False(t, unknown.Final().ContentKnown)
+	assert.Empty(t, unknown.CumulativeDiff)
+}
@@ -46,9 +46,7 @@ func (h *HierarchyBuilder) setEntryDepth(entry *models.ProcessedEntry, depth int
 		}
 	}
 
-	// Process children (though main conversation entries shouldn't have children)
-	for _, child := range entry.Children {
-		h.setEntryDepth(child, depth)
-	}
+	// Children are not walked: TaskEntries already lists every entry of a sidechain
+	// conversation, so walking them as well would visit long conversations quadratically
 }
 
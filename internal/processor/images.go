@@ -0,0 +1,55 @@
+package processor
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+
+	"github.com/brads3290/cclogviewer/internal/constants"
+	"github.com/brads3290/cclogviewer/internal/models"
+	"github.com/brads3290/cclogviewer/internal/utils"
+)
+
+// processImageBlock decodes an image content block so it can be embedded in the page.
+// Images that are too large, invalid or of an unsupported type are kept as placeholders.
+func processImageBlock(item map[string]interface{}) *models.ImageData {
+	source := utils.ExtractMap(item, "source")
+	image := &models.ImageData{
+		MediaType: utils.ExtractString(source, "media_type"),
+	}
+
+	if utils.ExtractString(source, "type") != constants.ImageSourceBase64 {
+		return omitImage(image, "unsupported image source")
+	}
+
+	if !constants.InlineImageMediaTypes[image.MediaType] {
+		return omitImage(image, fmt.Sprintf("unsupported image type %q", image.MediaType))
+	}
+
+	encoded := utils.ExtractString(source, "data")
+	image.Size = base64.StdEncoding.DecodedLen(len(encoded))
+	if image.Size > constants.MaxInlineImageSize {
+		return omitImage(image, "image too large")
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return omitImage(image, "invalid image data")
+	}
+	image.Size = len(decoded)
+
+	// Make sure the data really is the image type it claims to be
+	if http.DetectContentType(decoded) != image.MediaType {
+		return omitImage(image, "image data does not match its type")
+	}
+
+	image.Data = encoded
+	return image
+}
+
+// omitImage marks an image as omitted from the page
+func omitImage(image *models.ImageData, reason string) *models.ImageData {
+	image.Omitted = true
+	image.OmitReason = reason
+	return image
+}
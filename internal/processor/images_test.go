@@ -0,0 +1,103 @@
+package processor
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/brads3290/cclogviewer/internal/constants"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// onePixelPNG is a valid 1x1 transparent PNG
+const onePixelPNG = "iVBORw0KGgoAAAANSUhEUgAAAAEAAAABCAQAAAC1HAwCAAAAC0lEQVR42mNkYAAAAAYAAjCB0C8AAAAASUVORK5CYII="
+
+func imageBlock(sourceType, mediaType, data string) map[string]interface{} {
+	return map[string]interface{}{
+		"type": "image",
+		"source": map[string]interface{}{
+			"type":       sourceType,
+			"media_type": mediaType,
+			"data":       data,
+		},
+	}
+}
+
+func TestProcessImageBlock(t *testing.T) {
+	tests := []struct {
+		name        string
+		block       map[string]interface{}
+		wantOmitted bool
+	}{
+		{
+			name:  "valid png",
+			block: imageBlock("base64", "image/png", onePixelPNG),
+		},
+		{
+			name:        "unsupported media type",
+			block:       imageBlock("base64", "image/svg+xml", onePixelPNG),
+			wantOmitted: true,
+		},
+		{
+			name:        "data does not match media type",
+			block:       imageBlock("base64", "image/jpeg", onePixelPNG),
+			wantOmitted: true,
+		},
+		{
+			name:        "invalid base64",
+			block:       imageBlock("base64", "image/png", "not base64!"),
+			wantOmitted: true,
+		},
+		{
+			name:        "url source",
+			block:       imageBlock("url", "image/png", ""),
+			wantOmitted: true,
+		},
+		{
+			name:        "too large",
+			block:       imageBlock("base64", "image/png", strings.Repeat("A", constants.MaxInlineImageSize/3*4+8)),
+			wantOmitted: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			image := processImageBlock(tt.block)
+			require.NotNil(t, image)
+			assert.Equal(t, tt.wantOmitted, image.Omitted)
+			if tt.wantOmitted {
+				assert.Empty(t, image.Data, "omitted images must not keep their data")
+				assert.NotEmpty(t, image.OmitReason)
+			} else {
+				assert.Equal(t, onePixelPNG, image.Data)
+				assert.Greater(t, image.Size, 0)
+			}
+		})
+	}
+}
+
+func TestProcessUserMessage_Images(t *testing.T) {
+	msg := map[string]interface{}{
+		"role": "user",
+		"content": []interface{}{
+			map[string]interface{}{"type": "text", "text": "What is in this screenshot?"},
+			imageBlock("base64", "image/png", onePixelPNG),
+			map[string]interface{}{
+				"type":        "tool_result",
+				"tool_use_id": "tool-1",
+				"content":     []interface{}{imageBlock("base64", "image/png", onePixelPNG)},
+			},
+		},
+	}
+
+	content, blocks, toolResults := ProcessUserMessage(msg)
+
+	assert.Equal(t, "What is in this screenshot?", content)
+	require.Len(t, blocks, 2)
+	assert.Equal(t, "image", blocks[1].Type)
+	require.NotNil(t, blocks[1].Image)
+
+	require.Len(t, toolResults, 1)
+	require.Len(t, toolResults[0].Images, 1)
+	assert.False(t, toolResults[0].Images[0].Image.Omitted)
+}
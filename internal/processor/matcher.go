@@ -34,23 +34,30 @@ func (m *ToolCallMatcher) MatchToolCalls(entries []*models.ProcessedEntry) error
 		}
 	}
 
-	// Second, match tool results
+	// Second, match every tool result by its tool_use_id
 	for _, entry := range entries {
-		if entry.IsToolResult && entry.ToolResultID != "" {
+		for _, result := range toolResultsOf(entry) {
+			if result.ToolResultID == "" {
+				continue
+			}
+
 			var toolCall *models.ToolCall
 
-			if !entry.IsSidechain {
-				toolCall = mainToolCallMap[entry.ToolResultID]
+			if !result.IsSidechain {
+				toolCall = mainToolCallMap[result.ToolResultID]
 			} else {
-				toolCall = sidechainToolCallMap[entry.ToolResultID]
+				toolCall = sidechainToolCallMap[result.ToolResultID]
+			}
+
+			if toolCall == nil {
+				result.IsUnmatchedResult = true
+				continue
 			}
 
-			if toolCall != nil {
-				toolCall.Result = entry
-				// Check if the tool was interrupted
-				if entry.IsError && strings.Contains(strings.ToLower(entry.Content), constants.UserInterruptionPattern) {
-					toolCall.IsInterrupted = true
-				}
+			toolCall.Result = result
+			// Check if the tool was interrupted
+			if result.IsError && strings.Contains(strings.ToLower(result.Content), constants.UserInterruptionPattern) {
+				toolCall.IsInterrupted = true
 			}
 		}
 	}
@@ -58,23 +65,62 @@ func (m *ToolCallMatcher) MatchToolCalls(entries []*models.ProcessedEntry) error
 	return nil
 }
 
-// FilterRootEntries filters entries to only include root conversation entries
-func (m *ToolCallMatcher) FilterRootEntries(entries []*models.ProcessedEntry) []*models.ProcessedEntry {
-	var rootEntries []*models.ProcessedEntry
+// toolResultsOf returns the tool results carried by an entry.
+// Entries built without ToolResults count as a single result when flagged as one.
+func toolResultsOf(entry *models.ProcessedEntry) []*models.ProcessedEntry {
+	if len(entry.ToolResults) > 0 {
+		return entry.ToolResults
+	}
+	if entry.IsToolResult && entry.ToolResultID != "" {
+		return []*models.ProcessedEntry{entry}
+	}
+	return nil
+}
+
+// isConsumedToolResult reports whether every tool result of an entry was attached to a
+// tool call and the entry has nothing else left to display.
+func isConsumedToolResult(entry *models.ProcessedEntry, matchedToolCalls map[string]bool) bool {
+	results := toolResultsOf(entry)
+	if len(results) == 0 {
+		return false
+	}
 
-	// Build a set of tool result IDs that have been matched
-	matchedResults := make(map[string]bool)
+	// Text or images sent alongside the tool results still need to be shown
+	if len(entry.ToolResults) > 0 && len(entry.Blocks) > 0 {
+		return false
+	}
+
+	for _, result := range results {
+		if !matchedToolCalls[result.ToolResultID] {
+			return false
+		}
+	}
+	return true
+}
+
+// matchedToolCallIDs returns the IDs of all tool calls that have a result attached
+func matchedToolCallIDs(entries []*models.ProcessedEntry) map[string]bool {
+	matched := make(map[string]bool)
 	for _, entry := range entries {
 		for _, toolCall := range entry.ToolCalls {
 			if toolCall.Result != nil {
-				matchedResults[toolCall.Result.UUID] = true
+				matched[toolCall.ID] = true
 			}
 		}
 	}
+	return matched
+}
+
+// FilterRootEntries filters entries to only include root conversation entries
+func (m *ToolCallMatcher) FilterRootEntries(entries []*models.ProcessedEntry) []*models.ProcessedEntry {
+	var rootEntries []*models.ProcessedEntry
+
+	// Build a set of tool call IDs that have been matched with a result
+	matchedToolCalls := matchedToolCallIDs(entries)
 
 	// Include only non-sidechain entries that aren't matched tool results
 	for _, entry := range entries {
-		if !entry.IsSidechain && !matchedResults[entry.UUID] {
+		if !entry.IsSidechain && !isConsumedToolResult(entry, matchedToolCalls) {
 			rootEntries = append(rootEntries, entry)
 		}
 	}
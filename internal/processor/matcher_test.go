@@ -95,3 +95,68 @@ func TestFilterRootEntries(t *testing.T) {
 		t.Error("Expected entry 4 to be in root entries")
 	}
 }
+
+func TestProcessEntries_ParallelToolResults(t *testing.T) {
+	entries := []models.LogEntry{
+		{
+			UUID:      "msg-1",
+			Type:      constants.TypeAssistant,
+			Timestamp: "2024-01-01T10:00:00Z",
+			Message: []byte(`{"role":"assistant","content":[` +
+				`{"type":"tool_use","id":"tool-1","name":"Bash","input":{"command":"ls"}},` +
+				`{"type":"tool_use","id":"tool-2","name":"Bash","input":{"command":"pwd"}}]}`),
+		},
+		{
+			UUID:      "msg-2",
+			Type:      constants.TypeUser,
+			Timestamp: "2024-01-01T10:00:01Z",
+			Message: []byte(`{"role":"user","content":[` +
+				`{"type":"tool_result","tool_use_id":"tool-1","content":"a.txt"},` +
+				`{"type":"tool_result","tool_use_id":"tool-2","content":[{"type":"text","text":"/home"}]},` +
+				`{"type":"tool_result","tool_use_id":"tool-9","content":"orphan","is_error":true},` +
+				`{"type":"text","text":"Please continue"}]}`),
+		},
+	}
+
+	roots := ProcessEntries(entries)
+
+	if len(roots) != 2 {
+		t.Fatalf("Expected 2 root entries, got %d", len(roots))
+	}
+
+	toolCalls := roots[0].ToolCalls
+	if toolCalls[0].Result == nil || toolCalls[0].Result.Content != "a.txt" {
+		t.Errorf("Expected tool-1 to be matched with its own result, got %+v", toolCalls[0].Result)
+	}
+	if toolCalls[1].Result == nil || toolCalls[1].Result.Content != "/home" {
+		t.Errorf("Expected tool-2 to be matched with its own result, got %+v", toolCalls[1].Result)
+	}
+
+	user := roots[1]
+	if user.Content != "Please continue" {
+		t.Errorf("Expected text block to be kept, got %q", user.Content)
+	}
+
+	unmatched := user.UnmatchedResults()
+	if len(unmatched) != 1 || unmatched[0].ToolResultID != "tool-9" || !unmatched[0].IsError {
+		t.Errorf("Expected tool-9 to be reported as unmatched, got %+v", unmatched)
+	}
+}
+
+func TestFilterRootEntries_DropsFullyMatchedMessages(t *testing.T) {
+	result1 := &models.ProcessedEntry{UUID: "2", IsToolResult: true, ToolResultID: "tool-1"}
+	result2 := &models.ProcessedEntry{UUID: "2", IsToolResult: true, ToolResultID: "tool-2"}
+	entries := []*models.ProcessedEntry{
+		{UUID: "1", ToolCalls: []models.ToolCall{
+			{ID: "tool-1", Result: result1},
+			{ID: "tool-2", Result: result2},
+		}},
+		{UUID: "2", IsToolResult: true, ToolResults: []*models.ProcessedEntry{result1, result2}},
+	}
+
+	rootEntries := NewToolCallMatcher().FilterRootEntries(entries)
+
+	if len(rootEntries) != 1 || rootEntries[0].UUID != "1" {
+		t.Errorf("Expected only entry 1 to remain, got %d entries", len(rootEntries))
+	}
+}
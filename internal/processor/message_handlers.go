@@ -35,19 +35,48 @@ func processMessage(processed *models.ProcessedEntry, msg map[string]interface{}
 
 // handleUserMessage processes user messages
 func handleUserMessage(processed *models.ProcessedEntry, msg map[string]interface{}, entry models.LogEntry) error {
-	processed.Content = ProcessUserMessage(msg)
-	processed.IsToolResult = isToolResult(msg)
+	content, blocks, toolResults := ProcessUserMessage(msg)
+	processed.Content = content
+	processed.Blocks = blocks
+	processed.IsToolResult = len(toolResults) > 0
+
+	for _, block := range toolResults {
+		processed.ToolResults = append(processed.ToolResults, newToolResultEntry(processed, block))
+	}
+
+	// The structured payload can only be attributed when the message holds a single result
+	if len(processed.ToolResults) == 1 {
+		processed.ToolResults[0].ToolUseResult = entry.ToolUseResult
+	}
 
 	checkCaveatMessage(processed)
 	checkCommandMessage(processed)
-	extractToolResultData(processed, msg)
 
 	return nil
 }
 
+// newToolResultEntry creates the entry for a single tool result, sharing its message's metadata
+func newToolResultEntry(parent *models.ProcessedEntry, block ToolResultBlock) *models.ProcessedEntry {
+	return &models.ProcessedEntry{
+		UUID:         parent.UUID,
+		ParentUUID:   parent.ParentUUID,
+		Type:         parent.Type,
+		Timestamp:    parent.Timestamp,
+		RawTimestamp: parent.RawTimestamp,
+		Role:         parent.Role,
+		Content:      block.Content,
+		Blocks:       block.Images,
+		IsToolResult: true,
+		ToolResultID: block.ToolUseID,
+		IsSidechain:  parent.IsSidechain,
+		AgentID:      parent.AgentID,
+		IsError:      block.IsError,
+	}
+}
+
 // handleAssistantMessage processes assistant messages
 func handleAssistantMessage(processed *models.ProcessedEntry, msg map[string]interface{}, entry models.LogEntry) error {
-	processed.Content, processed.ToolCalls = ProcessAssistantMessage(msg, entry.CWD)
+	processed.Content, processed.ToolCalls, processed.Blocks = ProcessAssistantMessage(msg, entry.CWD)
 	return nil
 }
 
@@ -70,26 +99,6 @@ func checkCommandMessage(processed *models.ProcessedEntry) {
 	}
 }
 
-// extractToolResultData extracts tool result error status and ID
-func extractToolResultData(processed *models.ProcessedEntry, msg map[string]interface{}) {
-	if !processed.IsToolResult {
-		return
-	}
-
-	content, ok := msg["content"].([]interface{})
-	if !ok || len(content) == 0 {
-		return
-	}
-
-	toolResult, ok := content[0].(map[string]interface{})
-	if !ok {
-		return
-	}
-
-	processed.IsError = utils.ExtractBool(toolResult, "is_error")
-	processed.ToolResultID = utils.ExtractString(toolResult, "tool_use_id")
-}
-
 // TokenProcessor calculates and tracks token usage.
 type TokenProcessor struct{}
 
@@ -114,7 +123,8 @@ func (tp *TokenProcessor) extractUsageTokens(processed *models.ProcessedEntry, u
 	}
 
 	// Always estimate output tokens from content for accuracy
-	processed.OutputTokens = EstimateTokens(string(processed.Content))
+	processed.ThinkingTokens = tp.estimateThinkingTokens(processed)
+	processed.OutputTokens = EstimateTokens(string(processed.Content)) + processed.ThinkingTokens
 	processed.TokenCount = processed.OutputTokens
 
 	if cacheReadTokens, ok := usage["cache_read_input_tokens"].(float64); ok {
@@ -126,9 +136,28 @@ func (tp *TokenProcessor) extractUsageTokens(processed *models.ProcessedEntry, u
 	}
 }
 
+// estimateThinkingTokens estimates the tokens of all thinking blocks of an entry
+func (tp *TokenProcessor) estimateThinkingTokens(processed *models.ProcessedEntry) int {
+	tokens := 0
+	for _, block := range processed.Blocks {
+		if block.Type == constants.ContentTypeThinking {
+			tokens += EstimateTokens(block.Text)
+		}
+	}
+	return tokens
+}
+
 // estimateTokens estimates token counts when usage data is not available
 func (tp *TokenProcessor) estimateTokens(processed *models.ProcessedEntry) {
-	processed.TokenCount = EstimateTokens(string(processed.Content))
+	processed.ThinkingTokens = tp.estimateThinkingTokens(processed)
+	processed.TokenCount = EstimateTokens(string(processed.Content)) + processed.ThinkingTokens
+
+	// Tool results are estimated individually and counted towards their message
+	for _, result := range processed.ToolResults {
+		result.TokenCount = EstimateTokens(result.Content)
+		result.OutputTokens = result.TokenCount
+		processed.TokenCount += result.TokenCount
+	}
 
 	// For user messages, the estimated tokens are output tokens
 	if processed.Role == constants.RoleUser {
@@ -7,46 +7,91 @@ import (
 	"strings"
 )
 
-// ProcessUserMessage extracts content from user messages.
-func ProcessUserMessage(msg map[string]interface{}) string {
-	content := utils.ExtractString(msg, "content")
+// ToolResultBlock is a single tool_result content block of a user message.
+type ToolResultBlock struct {
+	ToolUseID string
+	Content   string
+	Images    []models.ContentBlock
+	IsError   bool
+}
 
-	// Check if content is an array
-	if contentArray, ok := msg["content"].([]interface{}); ok && len(contentArray) > 0 {
-		if contentItem, ok := contentArray[0].(map[string]interface{}); ok {
-			contentType := utils.ExtractString(contentItem, "type")
+// ProcessUserMessage extracts the text, the ordered text and image blocks, and every
+// tool result from user messages.
+func ProcessUserMessage(msg map[string]interface{}) (string, []models.ContentBlock, []ToolResultBlock) {
+	contentArray, ok := msg["content"].([]interface{})
+	if !ok {
+		// Plain string content
+		return utils.ExtractString(msg, "content"), nil, nil
+	}
 
-			switch contentType {
-			case constants.ContentTypeText:
-				// Handle text content (including interrupted messages)
-				text := utils.ExtractString(contentItem, "text")
+	var texts []string
+	var blocks []models.ContentBlock
+	var toolResults []ToolResultBlock
 
-				return text
-			case constants.ContentTypeToolResult:
-				// Handle tool result content
-				var toolContent string
-				if contentVal, ok := contentItem["content"].(string); ok {
-					toolContent = contentVal
-				} else if contentArray, ok := contentItem["content"].([]interface{}); ok && len(contentArray) > 0 {
-					// Handle array content (like from Task tool)
-					if textContent, ok := contentArray[0].(map[string]interface{}); ok {
-						toolContent = utils.ExtractString(textContent, "text")
-					}
-				}
-				return toolContent
+	for _, item := range contentArray {
+		contentItem, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		contentType := utils.ExtractString(contentItem, "type")
+		switch contentType {
+		case constants.ContentTypeText:
+			// Handle text content (including interrupted messages)
+			if text := utils.ExtractString(contentItem, "text"); text != "" {
+				texts = append(texts, text)
+				blocks = append(blocks, models.ContentBlock{Type: contentType, Text: text})
 			}
+		case constants.ContentTypeImage:
+			blocks = append(blocks, models.ContentBlock{Type: contentType, Image: processImageBlock(contentItem)})
+		case constants.ContentTypeToolResult:
+			content, images := extractToolResultContent(contentItem)
+			toolResults = append(toolResults, ToolResultBlock{
+				ToolUseID: utils.ExtractString(contentItem, "tool_use_id"),
+				Content:   content,
+				Images:    images,
+				IsError:   utils.ExtractBool(contentItem, "is_error"),
+			})
 		}
 	}
 
-	// Also check direct string content
+	return strings.Join(texts, "\n"), blocks, toolResults
+}
+
+// extractToolResultContent extracts the text and images of a tool_result block
+func extractToolResultContent(toolResult map[string]interface{}) (string, []models.ContentBlock) {
+	if content, ok := toolResult["content"].(string); ok {
+		return content, nil
+	}
+
+	// Handle array content (like from Task tool, or images returned by Read)
+	var texts []string
+	var images []models.ContentBlock
+	for _, item := range utils.ExtractSlice(toolResult, "content") {
+		contentItem, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
 
-	return content
+		switch utils.ExtractString(contentItem, "type") {
+		case constants.ContentTypeText:
+			texts = append(texts, utils.ExtractString(contentItem, "text"))
+		case constants.ContentTypeImage:
+			images = append(images, models.ContentBlock{
+				Type:  constants.ContentTypeImage,
+				Image: processImageBlock(contentItem),
+			})
+		}
+	}
+	return strings.Join(texts, "\n"), images
 }
 
-// ProcessAssistantMessage extracts content and tool calls from assistant messages.
-func ProcessAssistantMessage(msg map[string]interface{}, cwd string) (string, []models.ToolCall) {
+// ProcessAssistantMessage extracts content, tool calls and the ordered content blocks from assistant messages.
+func ProcessAssistantMessage(msg map[string]interface{}, cwd string) (string, []models.ToolCall, []models.ContentBlock) {
 	var content strings.Builder
 	var toolCalls []models.ToolCall
+	var blocks []models.ContentBlock
+	var toolCallBlocks []int // Indexes of tool_use blocks, resolved once toolCalls stops growing
 
 	if contentArray, ok := msg["content"].([]interface{}); ok {
 		for _, item := range contentArray {
@@ -58,15 +103,30 @@ func ProcessAssistantMessage(msg map[string]interface{}, cwd string) (string, []
 					text := utils.ExtractString(contentItem, "text")
 					if text != "" {
 						content.WriteString(text)
+						blocks = append(blocks, models.ContentBlock{Type: contentType, Text: text})
 					}
 				case constants.ContentTypeToolUse:
 					tool := ProcessToolUse(contentItem)
 					tool.CWD = cwd
 					toolCalls = append(toolCalls, tool)
+					toolCallBlocks = append(toolCallBlocks, len(blocks))
+					blocks = append(blocks, models.ContentBlock{Type: contentType})
+				case constants.ContentTypeThinking:
+					thinking := utils.ExtractString(contentItem, "thinking")
+					if thinking != "" {
+						blocks = append(blocks, models.ContentBlock{Type: contentType, Text: thinking})
+					}
+				case constants.ContentTypeRedactedThinking:
+					// The thinking is encrypted, only record that it happened
+					blocks = append(blocks, models.ContentBlock{Type: contentType})
 				}
 			}
 		}
 	}
 
-	return content.String(), toolCalls
+	for i, blockIdx := range toolCallBlocks {
+		blocks[blockIdx].ToolCall = &toolCalls[i]
+	}
+
+	return content.String(), toolCalls, blocks
 }
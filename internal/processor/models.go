@@ -10,6 +10,7 @@ type ProcessingState struct {
 	Entries        []*models.ProcessedEntry
 	ToolCallMap    map[string]*ToolCallContext
 	ParentChildMap map[string][]string
+	Summaries      []models.Summary
 	Index          int
 }
 
@@ -3,6 +3,7 @@ package processor
 import (
 	"log"
 
+	"github.com/brads3290/cclogviewer/internal/constants"
 	"github.com/brads3290/cclogviewer/internal/models"
 )
 
@@ -17,12 +18,28 @@ func initializeProcessingState(capacity int) *ProcessingState {
 
 // processAllEntries processes raw log entries into ProcessedEntry objects
 func processAllEntries(entries []models.LogEntry, state *ProcessingState, entryMap map[string]*models.ProcessedEntry) {
-	for i, entry := range entries {
-		state.Index = i
-		processed := processEntry(entry)
+	for _, entry := range entries {
+		addEntry(entry, state, entryMap)
+	}
+}
+
+// addEntry processes a single raw log entry and records it in the processing state
+func addEntry(entry models.LogEntry, state *ProcessingState, entryMap map[string]*models.ProcessedEntry) {
+	// Summaries describe the conversation rather than being part of it
+	if entry.Type == constants.EntryTypeSummary {
+		state.Summaries = append(state.Summaries, models.Summary{
+			Text:     entry.Summary,
+			LeafUUID: entry.LeafUUID,
+		})
+		return
+	}
+
+	processed := processEntry(entry)
+	if processed.UUID != "" {
 		entryMap[processed.UUID] = processed
-		state.Entries = append(state.Entries, processed)
 	}
+	state.Entries = append(state.Entries, processed)
+	state.Index++
 }
 
 // matchToolCallsWithResults matches tool calls with their corresponding results
@@ -33,10 +50,20 @@ func matchToolCallsWithResults(entries []*models.ProcessedEntry) {
 	}
 }
 
+// formatToolOutputs formats the result of every tool call, including those of subagents
+func formatToolOutputs(entries []*models.ProcessedEntry) {
+	toolProcessor := GetToolProcessor()
+	for _, entry := range entries {
+		for i := range entry.ToolCalls {
+			toolProcessor.FormatOutput(&entry.ToolCalls[i])
+		}
+	}
+}
+
 // processSidechainConversations processes Task tool sidechain conversations
-func processSidechainConversations(state *ProcessingState, entries []models.LogEntry, entryMap map[string]*models.ProcessedEntry) {
+func processSidechainConversations(state *ProcessingState, entryMap map[string]*models.ProcessedEntry) {
 	sidechainProc := NewSidechainProcessor()
-	if err := sidechainProc.ProcessSidechains(state.Entries, entries, entryMap); err != nil {
+	if err := sidechainProc.ProcessSidechains(state.Entries, entryMap); err != nil {
 		log.Printf("Error processing sidechains: %v", err)
 	}
 }
@@ -73,3 +100,46 @@ func buildFinalHierarchy(rootEntries []*models.ProcessedEntry) {
 		log.Printf("Error building hierarchy: %v", err)
 	}
 }
+
+// attachSummaries places each summary after the entry it covers so it can be shown as a
+// compaction marker, and returns the summary to use as the session title
+func attachSummaries(summaries []models.Summary, rootEntries []*models.ProcessedEntry, state *ProcessingState, entryMap map[string]*models.ProcessedEntry) string {
+	if len(summaries) == 0 {
+		return ""
+	}
+
+	// Tool results are shown inside the tool call of the entry that made it
+	resultOwners := make(map[string]*models.ProcessedEntry)
+	for _, entry := range state.Entries {
+		for _, toolCall := range entry.ToolCalls {
+			if toolCall.Result != nil {
+				resultOwners[toolCall.Result.UUID] = entry
+			}
+		}
+	}
+
+	rendered := make(map[string]bool)
+	for _, entry := range rootEntries {
+		rendered[entry.UUID] = true
+	}
+
+	title := summaries[len(summaries)-1].Text
+	for _, summary := range summaries {
+		target := entryMap[summary.LeafUUID]
+		if target == nil {
+			// The summary covers a conversation that is not in this log
+			continue
+		}
+
+		if !target.IsSidechain && !rendered[target.UUID] {
+			if owner := resultOwners[target.UUID]; owner != nil {
+				target = owner
+			}
+		}
+
+		target.Summaries = append(target.Summaries, summary)
+		title = summary.Text
+	}
+
+	return title
+}
@@ -1,13 +1,13 @@
 package processor
 
 import (
-	"encoding/json"
-	"fmt"
 	"github.com/brads3290/cclogviewer/internal/constants"
 	"github.com/brads3290/cclogviewer/internal/debug"
 	"github.com/brads3290/cclogviewer/internal/models"
+	"github.com/brads3290/cclogviewer/internal/utils"
 	"log"
 	"strings"
+	"time"
 )
 
 // SidechainProcessor handles Task tool sidechain conversation processing.
@@ -21,17 +21,20 @@ func NewSidechainProcessor() *SidechainProcessor {
 // TaskMatchContext holds context for Task tool sidechain matching.
 type TaskMatchContext struct {
 	ToolCall          *models.ToolCall
-	Entry             *models.LogEntry
-	OriginalEntries   []models.LogEntry
+	Entry             *models.ProcessedEntry
 	SidechainRoots    []*models.ProcessedEntry
 	EntryMap          map[string]*models.ProcessedEntry
 	MatchedSidechains map[string]bool
+
+	index *sidechainIndex
 }
 
-// ProcessSidechains processes sidechain conversations and matches them with Task tool calls
-func (s *SidechainProcessor) ProcessSidechains(entries []*models.ProcessedEntry, originalEntries []models.LogEntry, entryMap map[string]*models.ProcessedEntry) error {
+// ProcessSidechains processes sidechain conversations and matches them with Task tool calls.
+// Links are made from structural data in the log first (agent IDs, parentUuid chains and
+// timestamps), and only fall back to comparing prompt and result text when that isn't enough.
+func (s *SidechainProcessor) ProcessSidechains(entries []*models.ProcessedEntry, entryMap map[string]*models.ProcessedEntry) error {
 	// First, collect all sidechain roots
-	sidechainRoots := s.collectSidechainRoots(originalEntries, entryMap)
+	sidechainRoots := s.collectSidechainRoots(entries, entryMap)
 
 	if debug.Enabled {
 		log.Printf("Found %d sidechain roots", len(sidechainRoots))
@@ -39,44 +42,65 @@ func (s *SidechainProcessor) ProcessSidechains(entries []*models.ProcessedEntry,
 
 	// Build a map to track which sidechains have been matched
 	matchedSidechains := make(map[string]bool)
+	index := newSidechainIndex(entries)
+
+	// Collect every Task tool call before linking, so that a weak link made for one Task
+	// can't take a sidechain that structurally belongs to another
+	var tasks []*TaskMatchContext
+	for _, processed := range entries {
+		if processed.Role != constants.RoleAssistant {
+			continue
+		}
 
-	// Look through tool calls to match Task tools with their sidechains
-	for _, entry := range originalEntries {
-		if entry.Type == constants.TypeAssistant {
-			processed := entryMap[entry.UUID]
+		if debug.Enabled && len(processed.ToolCalls) > 0 {
+			log.Printf("Processing assistant entry %s (sidechain: %v) with %d tool calls",
+				processed.UUID, processed.IsSidechain, len(processed.ToolCalls))
+		}
 
-			if debug.Enabled && len(processed.ToolCalls) > 0 {
-				log.Printf("Processing assistant entry %s (sidechain: %v) with %d tool calls",
-					entry.UUID, entry.IsSidechain, len(processed.ToolCalls))
+		for i := range processed.ToolCalls {
+			toolCall := &processed.ToolCalls[i]
+			if toolCall.Name == constants.TaskToolName {
+				tasks = append(tasks, &TaskMatchContext{
+					ToolCall:          toolCall,
+					Entry:             processed,
+					SidechainRoots:    sidechainRoots,
+					EntryMap:          entryMap,
+					MatchedSidechains: matchedSidechains,
+					index:             index,
+				})
 			}
+		}
+	}
+
+	for _, ctx := range tasks {
+		s.linkByStructure(ctx)
+	}
 
-			for i := range processed.ToolCalls {
-				toolCall := &processed.ToolCalls[i]
-				if toolCall.Name == constants.TaskToolName {
-					ctx := &TaskMatchContext{
-						ToolCall:          toolCall,
-						Entry:             &entry,
-						OriginalEntries:   originalEntries,
-						SidechainRoots:    sidechainRoots,
-						EntryMap:          entryMap,
-						MatchedSidechains: matchedSidechains,
-					}
-					s.matchTaskWithSidechain(ctx)
-				}
+	// Each timestamp link can make the time window of another Task unambiguous
+	for linked := true; linked; {
+		linked = false
+		for _, ctx := range tasks {
+			if ctx.ToolCall.LinkMethod == "" && s.linkByTimestamp(ctx) {
+				linked = true
 			}
 		}
 	}
 
+	for _, ctx := range tasks {
+		if ctx.ToolCall.LinkMethod == "" {
+			s.matchTaskWithSidechain(ctx)
+		}
+	}
+
 	return nil
 }
 
 // collectSidechainRoots collects all sidechain root entries
-func (s *SidechainProcessor) collectSidechainRoots(entries []models.LogEntry, entryMap map[string]*models.ProcessedEntry) []*models.ProcessedEntry {
+func (s *SidechainProcessor) collectSidechainRoots(entries []*models.ProcessedEntry, entryMap map[string]*models.ProcessedEntry) []*models.ProcessedEntry {
 	var sidechainRoots []*models.ProcessedEntry
 
-	for _, entry := range entries {
-		processed := entryMap[entry.UUID]
-		if s.isSidechainRoot(processed) {
+	for _, processed := range entries {
+		if s.isSidechainRoot(processed, entryMap) {
 			sidechainRoots = append(sidechainRoots, processed)
 		}
 	}
@@ -84,12 +108,136 @@ func (s *SidechainProcessor) collectSidechainRoots(entries []models.LogEntry, en
 	return sidechainRoots
 }
 
-// isSidechainRoot checks if an entry is a sidechain root
-func (s *SidechainProcessor) isSidechainRoot(processed *models.ProcessedEntry) bool {
-	return processed.IsSidechain && processed.ParentUUID == "" && !processed.IsToolResult
+// isSidechainRoot checks if an entry starts a sidechain. Its parent is either missing,
+// a Task tool call ID, or an entry of the main conversation.
+func (s *SidechainProcessor) isSidechainRoot(processed *models.ProcessedEntry, entryMap map[string]*models.ProcessedEntry) bool {
+	if !processed.IsSidechain || processed.IsToolResult {
+		return false
+	}
+	if processed.ParentUUID == "" {
+		return true
+	}
+
+	parent := entryMap[processed.ParentUUID]
+	return parent == nil || !parent.IsSidechain
+}
+
+// linkByStructure links a Task using the agent ID of its result or the parentUuid of the sidechain
+func (s *SidechainProcessor) linkByStructure(ctx *TaskMatchContext) bool {
+	if agentID := taskAgentID(ctx.ToolCall); agentID != "" {
+		roots := s.unmatchedRoots(ctx, func(root *models.ProcessedEntry) bool {
+			return root.AgentID == agentID
+		})
+		if len(roots) > 0 {
+			s.linkTask(ctx, roots, constants.LinkMethodAgentID, constants.LinkConfidenceHigh)
+			return true
+		}
+	}
+
+	roots := s.unmatchedRoots(ctx, func(root *models.ProcessedEntry) bool {
+		return root.ParentUUID == ctx.ToolCall.ID
+	})
+
+	// A sidechain pointing at the message is only unambiguous if the message started one Task
+	if len(roots) == 0 && countTaskCalls(ctx.Entry) == 1 {
+		roots = s.unmatchedRoots(ctx, func(root *models.ProcessedEntry) bool {
+			return root.ParentUUID == ctx.Entry.UUID
+		})
+	}
+
+	if len(roots) > 0 {
+		s.linkTask(ctx, roots, constants.LinkMethodParentUUID, constants.LinkConfidenceHigh)
+		return true
+	}
+
+	return false
+}
+
+// linkByTimestamp links a Task to the only unmatched sidechain that started while the Task was running
+func (s *SidechainProcessor) linkByTimestamp(ctx *TaskMatchContext) bool {
+	if ctx.ToolCall.Result == nil {
+		return false
+	}
+
+	started, err := time.Parse(time.RFC3339, ctx.Entry.RawTimestamp)
+	if err != nil {
+		return false
+	}
+	finished, err := time.Parse(time.RFC3339, ctx.ToolCall.Result.RawTimestamp)
+	if err != nil {
+		return false
+	}
+
+	agentID := taskAgentID(ctx.ToolCall)
+	roots := s.unmatchedRoots(ctx, func(root *models.ProcessedEntry) bool {
+		if agentID != "" && root.AgentID != "" {
+			return false // The agent IDs differ, or the structural pass would have linked it
+		}
+		t, err := time.Parse(time.RFC3339, root.RawTimestamp)
+		return err == nil && !t.Before(started) && !t.After(finished)
+	})
+
+	if len(roots) != 1 {
+		return false
+	}
+
+	s.linkTask(ctx, roots, constants.LinkMethodTimestamp, constants.LinkConfidenceMedium)
+	return true
+}
+
+// unmatchedRoots returns the sidechain roots not linked to a Task yet that satisfy match
+func (s *SidechainProcessor) unmatchedRoots(ctx *TaskMatchContext, match func(*models.ProcessedEntry) bool) []*models.ProcessedEntry {
+	var roots []*models.ProcessedEntry
+	for _, root := range ctx.SidechainRoots {
+		if !ctx.MatchedSidechains[root.UUID] && match(root) {
+			roots = append(roots, root)
+		}
+	}
+	return roots
+}
+
+// linkTask attaches the conversations starting at roots to a Task tool call
+func (s *SidechainProcessor) linkTask(ctx *TaskMatchContext, roots []*models.ProcessedEntry, method, confidence string) {
+	ctx.ToolCall.TaskEntries = nil
+	for _, root := range roots {
+		ctx.ToolCall.TaskEntries = append(ctx.ToolCall.TaskEntries, collectSidechainEntries(root, ctx.index)...)
+		ctx.MatchedSidechains[root.UUID] = true
+	}
+	ctx.ToolCall.LinkMethod = method
+	ctx.ToolCall.LinkConfidence = confidence
+
+	if debug.Enabled {
+		log.Printf("Linked Task tool %s to %d sidechain root(s) by %s (entries: %d)",
+			ctx.ToolCall.ID, len(roots), method, len(ctx.ToolCall.TaskEntries))
+	}
 }
 
-// matchTaskWithSidechain matches a Task tool call with its corresponding sidechain conversation
+// taskAgentID returns the subagent ID recorded in a Task tool's structured result
+func taskAgentID(toolCall *models.ToolCall) string {
+	if toolCall.Result == nil {
+		return ""
+	}
+
+	payload, ok := toolCall.Result.ToolUseResult.(map[string]interface{})
+	if !ok {
+		return ""
+	}
+
+	return utils.ExtractString(payload, constants.ToolUseResultAgentIDKey)
+}
+
+// countTaskCalls counts the Task tool calls made by an entry
+func countTaskCalls(entry *models.ProcessedEntry) int {
+	count := 0
+	for _, toolCall := range entry.ToolCalls {
+		if toolCall.Name == constants.TaskToolName {
+			count++
+		}
+	}
+	return count
+}
+
+// matchTaskWithSidechain matches a Task tool call with its sidechain by comparing prompt and result text
 func (s *SidechainProcessor) matchTaskWithSidechain(ctx *TaskMatchContext) {
 	if debug.Enabled {
 		log.Printf("Found Task tool %s in entry %s (sidechain: %v)",
@@ -110,7 +258,7 @@ func (s *SidechainProcessor) matchTaskWithSidechain(ctx *TaskMatchContext) {
 	}
 
 	// Extract the result text from the tool result
-	taskResult := s.extractTaskResult(ctx.ToolCall, ctx.OriginalEntries)
+	taskResult := s.extractTaskResult(ctx.ToolCall)
 	if taskResult == "" {
 		if debug.Enabled {
 			log.Printf("Task tool %s has empty result, skipping", ctx.ToolCall.ID)
@@ -124,16 +272,15 @@ func (s *SidechainProcessor) matchTaskWithSidechain(ctx *TaskMatchContext) {
 
 	// Find the best matching sidechain
 	bestMatch, bestMatchScore := s.findBestMatchingSidechain(
-		ctx.ToolCall, taskPrompt, taskResult, ctx.SidechainRoots, ctx.EntryMap, ctx.MatchedSidechains,
+		ctx.ToolCall, taskPrompt, taskResult, ctx.SidechainRoots, ctx.index, ctx.MatchedSidechains,
 	)
 
 	if bestMatch != nil {
-		ctx.ToolCall.TaskEntries = collectSidechainEntries(bestMatch, ctx.EntryMap)
-		ctx.MatchedSidechains[bestMatch.UUID] = true
-		if debug.Enabled {
-			log.Printf("Matched Task tool %s to sidechain %s (score: %d, entries: %d)",
-				ctx.ToolCall.ID, bestMatch.UUID, bestMatchScore, len(ctx.ToolCall.TaskEntries))
+		confidence := constants.LinkConfidenceLow
+		if bestMatchScore == constants.PerfectMatchScore {
+			confidence = constants.LinkConfidenceMedium
 		}
+		s.linkTask(ctx, []*models.ProcessedEntry{bestMatch}, constants.LinkMethodText, confidence)
 	} else {
 		if debug.Enabled {
 			log.Printf("No match found for Task tool %s", ctx.ToolCall.ID)
@@ -161,74 +308,12 @@ func (s *SidechainProcessor) extractTaskPrompt(toolCall *models.ToolCall) string
 }
 
 // extractTaskResult extracts the result text from a Task tool's result
-func (s *SidechainProcessor) extractTaskResult(toolCall *models.ToolCall, originalEntries []models.LogEntry) string {
+func (s *SidechainProcessor) extractTaskResult(toolCall *models.ToolCall) string {
 	if toolCall.Result == nil {
 		return ""
 	}
 
-	// Find the original entry for this result
-	for _, e := range originalEntries {
-		if e.UUID != toolCall.Result.UUID {
-			continue
-		}
-
-		text, err := s.extractTextFromEntry(e)
-		if err == nil {
-			return text
-		}
-		break
-	}
-
-	return ""
-}
-
-// extractTextFromEntry extracts text content from a log entry
-func (s *SidechainProcessor) extractTextFromEntry(entry models.LogEntry) (string, error) {
-	var msg map[string]interface{}
-	if err := json.Unmarshal(entry.Message, &msg); err != nil {
-		return "", err
-	}
-
-	content, ok := s.getContentArray(msg)
-	if !ok {
-		return "", fmt.Errorf("no content array")
-	}
-
-	return s.extractTextFromContent(content)
-}
-
-// getContentArray safely extracts the content array from a message
-func (s *SidechainProcessor) getContentArray(msg map[string]interface{}) ([]interface{}, bool) {
-	content, ok := msg["content"].([]interface{})
-	if !ok || len(content) == 0 {
-		return nil, false
-	}
-	return content, true
-}
-
-// extractTextFromContent extracts text from content array
-func (s *SidechainProcessor) extractTextFromContent(content []interface{}) (string, error) {
-	toolResult, ok := content[0].(map[string]interface{})
-	if !ok {
-		return "", fmt.Errorf("invalid tool result format")
-	}
-
-	resultContent, ok := toolResult["content"].([]interface{})
-	if !ok || len(resultContent) == 0 {
-		return "", fmt.Errorf("no result content")
-	}
-
-	textContent, ok := resultContent[0].(map[string]interface{})
-	if !ok {
-		return "", fmt.Errorf("invalid text content format")
-	}
-
-	text, ok := textContent["text"].(string)
-	if !ok {
-		return "", fmt.Errorf("no text field")
-	}
-
-	return text, nil
+	return toolCall.Result.Content
 }
 
 // canCheckPrefixMatch checks if both strings are long enough for prefix matching
@@ -246,7 +331,7 @@ func (s *SidechainProcessor) findBestMatchingSidechain(
 	toolCall *models.ToolCall,
 	taskPrompt, taskResult string,
 	sidechainRoots []*models.ProcessedEntry,
-	entryMap map[string]*models.ProcessedEntry,
+	index *sidechainIndex,
 	matchedSidechains map[string]bool,
 ) (*models.ProcessedEntry, int) {
 	var bestMatch *models.ProcessedEntry
@@ -263,8 +348,7 @@ func (s *SidechainProcessor) findBestMatchingSidechain(
 		}
 
 		// Get first user message and last assistant message from sidechain
-		firstUser := getFirstUserMessage(sidechain, entryMap)
-		lastAssistant := getLastAssistantMessage(sidechain, entryMap)
+		firstUser, lastAssistant := index.conversationText(sidechain)
 
 		if firstUser == "" || lastAssistant == "" {
 			if debug.Enabled {
@@ -331,5 +415,3 @@ func (s *SidechainProcessor) calculateMatchScore(
 
 	return score
 }
-
-// collectSidechainEntries collects all entries in a sidechain conversation
@@ -0,0 +1,141 @@
+package processor
+
+import (
+	"time"
+
+	"github.com/brads3290/cclogviewer/internal/constants"
+	"github.com/brads3290/cclogviewer/internal/models"
+)
+
+// sidechainIndex maps sidechain entries to their children. It is built once per session so
+// that walking a sidechain conversation doesn't scan every entry for each node it visits.
+type sidechainIndex struct {
+	children            map[string][]*models.ProcessedEntry // Sidechain children by parent UUID, in log order
+	attachedToolResults map[string]bool                     // Tool call IDs of sidechain results attached to their calls
+	texts               map[string]sidechainText            // Cached conversation text by root UUID
+}
+
+// sidechainText is the text used to match a sidechain conversation with its Task call
+type sidechainText struct {
+	firstUser     string
+	lastAssistant string
+}
+
+// newSidechainIndex indexes the sidechain entries of a session
+func newSidechainIndex(entries []*models.ProcessedEntry) *sidechainIndex {
+	index := &sidechainIndex{
+		children: make(map[string][]*models.ProcessedEntry),
+		texts:    make(map[string]sidechainText),
+	}
+
+	var sidechainEntries []*models.ProcessedEntry
+	for _, entry := range entries {
+		if !entry.IsSidechain {
+			continue
+		}
+		sidechainEntries = append(sidechainEntries, entry)
+		if entry.ParentUUID != "" {
+			index.children[entry.ParentUUID] = append(index.children[entry.ParentUUID], entry)
+		}
+	}
+	index.attachedToolResults = matchedToolCallIDs(sidechainEntries)
+
+	return index
+}
+
+// conversationText returns the first user and last assistant message of the conversation
+// starting at root
+func (i *sidechainIndex) conversationText(root *models.ProcessedEntry) (string, string) {
+	text, ok := i.texts[root.UUID]
+	if !ok {
+		text = sidechainText{
+			firstUser:     getFirstUserMessage(root, i),
+			lastAssistant: getLastAssistantMessage(root, i),
+		}
+		i.texts[root.UUID] = text
+	}
+	return text.firstUser, text.lastAssistant
+}
+
+// collectSidechainEntries returns the conversation starting at root in display order and
+// links each entry to its children
+func collectSidechainEntries(root *models.ProcessedEntry, index *sidechainIndex) []*models.ProcessedEntry {
+	var result []*models.ProcessedEntry
+
+	var buildTree func(entry *models.ProcessedEntry, skipEntry bool)
+	buildTree = func(entry *models.ProcessedEntry, skipEntry bool) {
+		// Add to result only if we're not skipping this entry
+		if !skipEntry {
+			result = append(result, entry)
+		}
+
+		entry.Children = append(entry.Children, index.children[entry.UUID]...)
+
+		for _, child := range index.children[entry.UUID] {
+			// Skip tool results that have been attached to tool calls when adding to result,
+			// but still process their children
+			shouldSkip := isConsumedToolResult(child, index.attachedToolResults)
+			buildTree(child, shouldSkip)
+		}
+	}
+
+	buildTree(root, false)
+	return result
+}
+
+// getFirstUserMessage finds the first user message in a sidechain conversation
+func getFirstUserMessage(root *models.ProcessedEntry, index *sidechainIndex) string {
+	// First check if root itself is a user message
+	if root.Role == constants.RoleUser {
+		return extractContent(root)
+	}
+
+	// Otherwise, look for the first user message in the tree
+	var findFirstUser func(entry *models.ProcessedEntry) string
+	findFirstUser = func(entry *models.ProcessedEntry) string {
+		// Check children first (in order)
+		for _, child := range index.children[entry.UUID] {
+			if child.Role == constants.RoleUser {
+				return extractContent(child)
+			}
+		}
+
+		// Then recursively check children's children
+		for _, child := range index.children[entry.UUID] {
+			if result := findFirstUser(child); result != "" {
+				return result
+			}
+		}
+
+		return ""
+	}
+
+	return findFirstUser(root)
+}
+
+// getLastAssistantMessage finds the last assistant message in a sidechain conversation
+func getLastAssistantMessage(root *models.ProcessedEntry, index *sidechainIndex) string {
+	var lastAssistantContent string
+	var lastAssistantTime time.Time
+
+	var findLastAssistant func(entry *models.ProcessedEntry)
+	findLastAssistant = func(entry *models.ProcessedEntry) {
+		// Check if this is an assistant message
+		if entry.Role == constants.RoleAssistant && !entry.IsToolResult {
+			// Parse timestamp
+			if t, err := time.Parse(time.RFC3339, entry.RawTimestamp); err == nil {
+				if lastAssistantContent == "" || t.After(lastAssistantTime) {
+					lastAssistantContent = extractContent(entry)
+					lastAssistantTime = t
+				}
+			}
+		}
+
+		for _, child := range index.children[entry.UUID] {
+			findLastAssistant(child)
+		}
+	}
+
+	findLastAssistant(root)
+	return lastAssistantContent
+}
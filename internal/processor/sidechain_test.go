@@ -0,0 +1,251 @@
+package processor
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/brads3290/cclogviewer/internal/constants"
+	"github.com/brads3290/cclogviewer/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func taskCallEntry(uuid, timestamp string, toolIDs ...string) models.LogEntry {
+	content := ""
+	for i, id := range toolIDs {
+		if i > 0 {
+			content += ","
+		}
+		content += fmt.Sprintf(`{"type":"tool_use","id":%q,"name":"Task","input":{"description":"Search","prompt":"Find the config loader"}}`, id)
+	}
+	return models.LogEntry{
+		UUID:      uuid,
+		Type:      constants.TypeAssistant,
+		Timestamp: timestamp,
+		Message:   []byte(`{"role":"assistant","content":[` + content + `]}`),
+	}
+}
+
+func taskResultEntry(uuid, timestamp, toolID, result string, toolUseResult interface{}) models.LogEntry {
+	return models.LogEntry{
+		UUID:          uuid,
+		Type:          constants.TypeUser,
+		Timestamp:     timestamp,
+		ToolUseResult: toolUseResult,
+		Message:       []byte(fmt.Sprintf(`{"role":"user","content":[{"type":"tool_result","tool_use_id":%q,"content":%q}]}`, toolID, result)),
+	}
+}
+
+func sidechainEntry(uuid, parent, agentID, role, timestamp, text string) models.LogEntry {
+	entry := models.LogEntry{
+		UUID:        uuid,
+		Type:        role,
+		Timestamp:   timestamp,
+		IsSidechain: true,
+		AgentID:     agentID,
+		Message:     []byte(fmt.Sprintf(`{"role":%q,"content":[{"type":"text","text":%q}]}`, role, text)),
+	}
+	if parent != "" {
+		entry.ParentUUID = &parent
+	}
+	return entry
+}
+
+func findToolCall(t *testing.T, entries []*models.ProcessedEntry, id string) *models.ToolCall {
+	t.Helper()
+	for _, entry := range entries {
+		for i := range entry.ToolCalls {
+			if entry.ToolCalls[i].ID == id {
+				return &entry.ToolCalls[i]
+			}
+		}
+	}
+	t.Fatalf("tool call %s not found", id)
+	return nil
+}
+
+func TestProcessSidechains_AgentIDLinksParallelTasks(t *testing.T) {
+	// Both subagents get the same prompt and give the same answer, so only the agent ID tells them apart
+	entries := []models.LogEntry{
+		taskCallEntry("msg-1", "2024-01-01T10:00:00Z", "task-a", "task-b"),
+		sidechainEntry("b-1", "", "agent-b", constants.TypeUser, "2024-01-01T10:00:01Z", "Find the config loader"),
+		sidechainEntry("a-1", "", "agent-a", constants.TypeUser, "2024-01-01T10:00:01Z", "Find the config loader"),
+		sidechainEntry("b-2", "b-1", "agent-b", constants.TypeAssistant, "2024-01-01T10:00:02Z", "It is in config.go"),
+		sidechainEntry("a-2", "a-1", "agent-a", constants.TypeAssistant, "2024-01-01T10:00:02Z", "It is in config.go"),
+		taskResultEntry("res-a", "2024-01-01T10:00:03Z", "task-a", "It is in config.go", map[string]interface{}{"agentId": "agent-a"}),
+		taskResultEntry("res-b", "2024-01-01T10:00:04Z", "task-b", "It is in config.go", map[string]interface{}{"agentId": "agent-b"}),
+	}
+
+	processed := ProcessEntries(entries)
+
+	taskA := findToolCall(t, processed, "task-a")
+	require.NotEmpty(t, taskA.TaskEntries)
+	assert.Equal(t, "a-1", taskA.TaskEntries[0].UUID)
+	assert.Equal(t, constants.LinkMethodAgentID, taskA.LinkMethod)
+	assert.Equal(t, constants.LinkConfidenceHigh, taskA.LinkConfidence)
+
+	taskB := findToolCall(t, processed, "task-b")
+	require.NotEmpty(t, taskB.TaskEntries)
+	assert.Equal(t, "b-1", taskB.TaskEntries[0].UUID)
+	assert.Equal(t, constants.LinkMethodAgentID, taskB.LinkMethod)
+}
+
+func TestProcessSidechains_ParentUUIDLink(t *testing.T) {
+	entries := []models.LogEntry{
+		taskCallEntry("msg-1", "2024-01-01T10:00:00Z", "task-1"),
+		sidechainEntry("sc-1", "task-1", "", constants.TypeUser, "2024-01-01T10:00:01Z", "Find the config loader"),
+		sidechainEntry("sc-2", "sc-1", "", constants.TypeAssistant, "2024-01-01T10:00:02Z", "Something unrelated"),
+		taskResultEntry("res-1", "2024-01-01T10:00:03Z", "task-1", "Truncated...", nil),
+	}
+
+	processed := ProcessEntries(entries)
+
+	task := findToolCall(t, processed, "task-1")
+	require.Len(t, task.TaskEntries, 2)
+	assert.Equal(t, constants.LinkMethodParentUUID, task.LinkMethod)
+	assert.Equal(t, constants.LinkConfidenceHigh, task.LinkConfidence)
+}
+
+func TestProcessSidechains_TimestampLink(t *testing.T) {
+	entries := []models.LogEntry{
+		taskCallEntry("msg-1", "2024-01-01T10:00:00Z", "task-1"),
+		sidechainEntry("sc-1", "", "", constants.TypeUser, "2024-01-01T10:00:01Z", "A different prompt"),
+		sidechainEntry("sc-2", "sc-1", "", constants.TypeAssistant, "2024-01-01T10:00:02Z", "A different answer"),
+		taskResultEntry("res-1", "2024-01-01T10:00:03Z", "task-1", "It is in config.go", nil),
+		taskCallEntry("msg-2", "2024-01-01T10:01:00Z", "task-2"),
+		sidechainEntry("sc-3", "", "", constants.TypeUser, "2024-01-01T10:01:01Z", "Another prompt"),
+		sidechainEntry("sc-4", "sc-3", "", constants.TypeAssistant, "2024-01-01T10:01:02Z", "Another answer"),
+		taskResultEntry("res-2", "2024-01-01T10:01:03Z", "task-2", "It is in main.go", nil),
+	}
+
+	processed := ProcessEntries(entries)
+
+	task1 := findToolCall(t, processed, "task-1")
+	require.NotEmpty(t, task1.TaskEntries)
+	assert.Equal(t, "sc-1", task1.TaskEntries[0].UUID)
+	assert.Equal(t, constants.LinkMethodTimestamp, task1.LinkMethod)
+	assert.Equal(t, constants.LinkConfidenceMedium, task1.LinkConfidence)
+
+	task2 := findToolCall(t, processed, "task-2")
+	require.NotEmpty(t, task2.TaskEntries)
+	assert.Equal(t, "sc-3", task2.TaskEntries[0].UUID)
+}
+
+func TestProcessSidechains_TextFallback(t *testing.T) {
+	// Both sidechains started while both Tasks were running, so only the text can tell them apart
+	entries := []models.LogEntry{
+		{
+			UUID:      "msg-1",
+			Type:      constants.TypeAssistant,
+			Timestamp: "2024-01-01T10:00:00Z",
+			Message: []byte(`{"role":"assistant","content":[` +
+				`{"type":"tool_use","id":"task-1","name":"Task","input":{"prompt":"Find the config loader"}},` +
+				`{"type":"tool_use","id":"task-2","name":"Task","input":{"prompt":"Find the HTTP handlers"}}]}`),
+		},
+		sidechainEntry("sc-2", "", "", constants.TypeUser, "2024-01-01T10:00:01Z", "Find the HTTP handlers"),
+		sidechainEntry("sc-1", "", "", constants.TypeUser, "2024-01-01T10:00:01Z", "Find the config loader"),
+		sidechainEntry("sc-1b", "sc-1", "", constants.TypeAssistant, "2024-01-01T10:00:02Z", "It is in config.go"),
+		sidechainEntry("sc-2b", "sc-2", "", constants.TypeAssistant, "2024-01-01T10:00:02Z", "They are in server.go"),
+		taskResultEntry("res-1", "2024-01-01T10:00:03Z", "task-1", "It is in config.go", nil),
+		taskResultEntry("res-2", "2024-01-01T10:00:04Z", "task-2", "Not found", nil),
+	}
+
+	processed := ProcessEntries(entries)
+
+	task1 := findToolCall(t, processed, "task-1")
+	require.NotEmpty(t, task1.TaskEntries)
+	assert.Equal(t, "sc-1", task1.TaskEntries[0].UUID)
+	assert.Equal(t, constants.LinkMethodText, task1.LinkMethod)
+	assert.Equal(t, constants.LinkConfidenceMedium, task1.LinkConfidence)
+
+	task2 := findToolCall(t, processed, "task-2")
+	require.NotEmpty(t, task2.TaskEntries)
+	assert.Equal(t, "sc-2", task2.TaskEntries[0].UUID)
+	assert.Equal(t, constants.LinkConfidenceLow, task2.LinkConfidence)
+}
+
+// syntheticTaskSession builds a session in which one message starts the given number of parallel
+// Tasks, each with a subagent conversation of entriesPerTask entries. The subagents all run at the
+// same time and record no agent IDs, so every Task has to be linked by comparing text.
+func syntheticTaskSession(tasks, entriesPerTask int) []models.LogEntry {
+	var calls []string
+	for i := 0; i < tasks; i++ {
+		calls = append(calls, fmt.Sprintf(`{"type":"tool_use","id":"task-%d","name":"Task","input":{"prompt":"Investigate area %d"}}`, i, i))
+	}
+	entries := []models.LogEntry{{
+		UUID:      "msg-0",
+		Type:      constants.TypeAssistant,
+		Timestamp: "2024-01-01T10:00:00Z",
+		Message:   []byte(`{"role":"assistant","content":[` + strings.Join(calls, ",") + `]}`),
+	}}
+
+	for i := 0; i < tasks; i++ {
+		parent := ""
+		for j := 0; j < entriesPerTask; j++ {
+			uuid := fmt.Sprintf("sc-%d-%d", i, j)
+			timestamp := fmt.Sprintf("2024-01-01T10:%02d:%02dZ", 1+j/60, j%60)
+			switch {
+			case j == 0:
+				entries = append(entries, sidechainEntry(uuid, parent, "", constants.TypeUser, timestamp, fmt.Sprintf("Investigate area %d", i)))
+			case j == entriesPerTask-1:
+				entries = append(entries, sidechainEntry(uuid, parent, "", constants.TypeAssistant, timestamp, fmt.Sprintf("Area %d is fine", i)))
+			case j%2 == 1:
+				entries = append(entries, sidechainEntry(uuid, parent, "", constants.TypeAssistant, timestamp, fmt.Sprintf("Step %d of area %d", j, i)))
+			default:
+				entries = append(entries, sidechainEntry(uuid, parent, "", constants.TypeUser, timestamp, "Continue"))
+			}
+			parent = uuid
+		}
+	}
+
+	for i := 0; i < tasks; i++ {
+		entries = append(entries, taskResultEntry(fmt.Sprintf("res-%d", i), "2024-01-01T11:00:00Z",
+			fmt.Sprintf("task-%d", i), fmt.Sprintf("Area %d is fine", i), nil))
+	}
+
+	return entries
+}
+
+func TestProcessSidechains_SyntheticSession(t *testing.T) {
+	processed := ProcessEntries(syntheticTaskSession(5, 20))
+
+	for i := 0; i < 5; i++ {
+		task := findToolCall(t, processed, fmt.Sprintf("task-%d", i))
+		require.Len(t, task.TaskEntries, 20)
+		assert.Equal(t, fmt.Sprintf("sc-%d-0", i), task.TaskEntries[0].UUID)
+		assert.Equal(t, fmt.Sprintf("sc-%d-19", i), task.TaskEntries[19].UUID)
+	}
+}
+
+func BenchmarkProcessSidechains(b *testing.B) {
+	sizes := []struct{ tasks, entriesPerTask int }{
+		{10, 100},
+		{20, 250},
+		{50, 100},
+	}
+
+	for _, size := range sizes {
+		entries := syntheticTaskSession(size.tasks, size.entriesPerTask)
+		b.Run(fmt.Sprintf("%dx%d", size.tasks, size.entriesPerTask), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				ProcessEntries(entries)
+			}
+		})
+	}
+}
+
+func BenchmarkSidechainLinking(b *testing.B) {
+	entries := syntheticTaskSession(20, 250)
+
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		state := initializeProcessingState(len(entries))
+		entryMap := make(map[string]*models.ProcessedEntry)
+		processAllEntries(entries, state, entryMap)
+		matchToolCallsWithResults(state.Entries)
+		b.StartTimer()
+
+		processSidechainConversations(state, entryMap)
+	}
+}
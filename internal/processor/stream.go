@@ -0,0 +1,41 @@
+package processor
+
+import (
+	"github.com/brads3290/cclogviewer/internal/models"
+)
+
+// StreamProcessor builds the conversation hierarchy from log entries added one at a time.
+// Each entry is converted to a ProcessedEntry as soon as it is added, so the raw JSON of
+// the log is never held in memory as a whole.
+//
+// Memory is only bounded for the raw log: every ProcessedEntry, with its formatted tool
+// calls, is kept until Finish, since tool results, sidechains and file histories are
+// linked across the whole session and the page is rendered from all entries at once.
+type StreamProcessor struct {
+	state    *ProcessingState
+	entryMap map[string]*models.ProcessedEntry
+}
+
+// NewStreamProcessor creates a new stream processor
+func NewStreamProcessor() *StreamProcessor {
+	return &StreamProcessor{
+		state:    initializeProcessingState(0),
+		entryMap: make(map[string]*models.ProcessedEntry),
+	}
+}
+
+// Add processes a single log entry
+func (p *StreamProcessor) Add(entry models.LogEntry) {
+	addEntry(entry, p.state, p.entryMap)
+}
+
+// Finish links tool calls, results and sidechains and returns the root entries.
+// The processor must not be used after Finish has been called.
+func (p *StreamProcessor) Finish() []*models.ProcessedEntry {
+	return p.FinishSession().Entries
+}
+
+// FinishSession is like Finish but also returns the session title and summaries.
+func (p *StreamProcessor) FinishSession() *models.Session {
+	return finishProcessing(p.state, p.entryMap)
+}
@@ -0,0 +1,85 @@
+package processor
+
+import (
+	"testing"
+
+	"github.com/brads3290/cclogviewer/internal/constants"
+	"github.com/brads3290/cclogviewer/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreamProcessor_MatchesProcessEntries(t *testing.T) {
+	entries := []models.LogEntry{
+		{
+			UUID:      "msg-1",
+			Type:      constants.TypeUser,
+			Timestamp: "2024-01-01T10:00:00Z",
+			Message:   []byte(`{"role":"user","content":"List files"}`),
+		},
+		{
+			UUID:      "msg-2",
+			Type:      constants.TypeAssistant,
+			Timestamp: "2024-01-01T10:00:01Z",
+			Message:   []byte(`{"role":"assistant","content":[{"type":"tool_use","id":"tool-1","name":"Bash","input":{"command":"ls"}}]}`),
+		},
+		{
+			UUID:      "msg-3",
+			Type:      constants.TypeUser,
+			Timestamp: "2024-01-01T10:00:02Z",
+			Message:   []byte(`{"role":"user","content":[{"type":"tool_result","tool_use_id":"tool-1","content":"a.txt"}]}`),
+		},
+	}
+
+	sp := NewStreamProcessor()
+	for _, entry := range entries {
+		sp.Add(entry)
+	}
+	streamed := sp.Finish()
+	batched := ProcessEntries(entries)
+
+	require.Len(t, streamed, 2)
+	require.Len(t, batched, len(streamed))
+	for i := range streamed {
+		assert.Equal(t, batched[i].UUID, streamed[i].UUID)
+		assert.Equal(t, batched[i].Content, streamed[i].Content)
+	}
+
+	require.Len(t, streamed[1].ToolCalls, 1)
+	require.NotNil(t, streamed[1].ToolCalls[0].Result)
+	assert.Equal(t, "a.txt", streamed[1].ToolCalls[0].Result.Content)
+	assert.True(t, streamed[1].ToolCalls[0].Inline)
+	assert.Contains(t, string(streamed[1].ToolCalls[0].Output), `<div class="bash-output">a.txt</div>`)
+}
+
+func TestStreamProcessor_Summaries(t *testing.T) {
+	entries := []models.LogEntry{
+		{Type: constants.EntryTypeSummary, Summary: "Old conversation", LeafUUID: "elsewhere"},
+		{Type: constants.EntryTypeSummary, Summary: "List files", LeafUUID: "msg-2"},
+		{
+			UUID:      "msg-1",
+			Type:      constants.TypeUser,
+			Timestamp: "2024-01-01T10:00:00Z",
+			Message:   []byte(`{"role":"user","content":"List files"}`),
+		},
+		{
+			UUID:      "msg-2",
+			Type:      constants.TypeAssistant,
+			Timestamp: "2024-01-01T10:00:01Z",
+			Message:   []byte(`{"role":"assistant","content":"Done"}`),
+		},
+	}
+
+	sp := NewStreamProcessor()
+	for _, entry := range entries {
+		sp.Add(entry)
+	}
+	session := sp.FinishSession()
+
+	assert.Equal(t, "List files", session.Title)
+	assert.Len(t, session.Summaries, 2)
+	require.Len(t, session.Entries, 2)
+	assert.Empty(t, session.Entries[0].Summaries)
+	require.Len(t, session.Entries[1].Summaries, 1)
+	assert.Equal(t, "List files", session.Entries[1].Summaries[0].Text)
+}
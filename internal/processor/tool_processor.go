@@ -1,8 +1,6 @@
 package processor
 
 import (
-	"html/template"
-
 	"github.com/brads3290/cclogviewer/internal/models"
 	"github.com/brads3290/cclogviewer/internal/processor/tools"
 	"github.com/brads3290/cclogviewer/internal/utils"
@@ -44,8 +42,8 @@ func (tp *ToolProcessor) ProcessToolCall(toolCall *models.ToolCall) {
 		// Format the input
 		formattedInput, err := tp.registry.Format(toolCall.Name, input)
 		if err != nil {
-			// Fallback to empty on error
-			toolCall.Input = template.HTML("")
+			// Fall back to the input as a JSON tree
+			toolCall.Input = tools.FormatGenericInput(input)
 		} else {
 			toolCall.Input = formattedInput
 		}
@@ -55,6 +53,39 @@ func (tp *ToolProcessor) ProcessToolCall(toolCall *models.ToolCall) {
 	}
 }
 
+// FormatEditAtLines formats the input of an Edit or MultiEdit call again, now that the file
+// lines of its edits are known
+func (tp *ToolProcessor) FormatEditAtLines(toolCall *models.ToolCall) {
+	input, ok := toolCall.RawInput.(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	if formattedInput, err := tp.registry.FormatWithEditLines(toolCall.Name, input, toolCall.EditLines); err == nil {
+		toolCall.Input = formattedInput
+	}
+}
+
+// FormatOutput formats the result of a tool call, once results have been matched with their calls
+func (tp *ToolProcessor) FormatOutput(toolCall *models.ToolCall) {
+	input, _ := toolCall.RawInput.(map[string]interface{})
+	output := &tools.ToolOutput{CWD: toolCall.CWD}
+	if toolCall.Result != nil {
+		output.HasResult = true
+		output.Content = toolCall.Result.Content
+		output.IsError = toolCall.Result.IsError
+		output.ToolUseResult = toolCall.Result.ToolUseResult
+	}
+
+	formattedOutput, err := tp.registry.FormatOutput(toolCall.Name, input, output)
+	if err != nil {
+		// Fall back to the result as text
+		formattedOutput = tools.FormatGenericOutput(output)
+	}
+	toolCall.Output = formattedOutput
+	toolCall.Inline = tp.registry.IsInline(toolCall.Name)
+}
+
 // ProcessToolUseWithRegistry processes a tool use message and returns a ToolCall
 // This replaces the standalone ProcessToolUse function
 func (tp *ToolProcessor) ProcessToolUseWithRegistry(toolUse map[string]interface{}) models.ToolCall {
@@ -62,6 +93,10 @@ func (tp *ToolProcessor) ProcessToolUseWithRegistry(toolUse map[string]interface
 		ID:   utils.ExtractString(toolUse, "id"),
 		Name: utils.ExtractString(toolUse, "name"),
 	}
+	if server, name, ok := tools.SplitMCPToolName(tool.Name); ok {
+		tool.MCPServer = server
+		tool.MCPTool = name
+	}
 
 	if input, ok := toolUse["input"].(map[string]interface{}); ok {
 		tool.RawInput = input // Store raw input for later use
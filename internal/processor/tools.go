@@ -18,6 +18,12 @@ func init() {
 	registry.Register(formatters.NewReadFormatter())
 	registry.Register(formatters.NewBashFormatter())
 	registry.Register(formatters.NewTodoWriteFormatter())
+	registry.Register(formatters.NewGrepFormatter())
+	registry.Register(formatters.NewGlobFormatter())
+	registry.Register(formatters.NewLSFormatter())
+	registry.Register(formatters.NewWebSearchFormatter())
+	registry.Register(formatters.NewWebFetchFormatter())
+	registry.Register(formatters.NewNotebookEditFormatter())
 }
 
 // ProcessToolUse processes a tool invocation and formats its display.
@@ -25,3 +31,8 @@ func ProcessToolUse(toolUse map[string]interface{}) models.ToolCall {
 	return GetToolProcessor().ProcessToolUseWithRegistry(toolUse)
 }
 
+// LoadToolFormatters registers the user-defined formatters of a directory, replacing
+// built-in formatters of the same tool name.
+func LoadToolFormatters(dir string) error {
+	return registry.LoadDir(dir)
+}
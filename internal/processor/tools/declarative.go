@@ -0,0 +1,271 @@
+package tools
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"html/template"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/brads3290/cclogviewer/internal/highlight"
+	"github.com/brads3290/cclogviewer/internal/markdown"
+	"gopkg.in/yaml.v3"
+)
+
+// DeclarativeSpec describes a tool formatter written as a YAML or JSON file instead of Go
+type DeclarativeSpec struct {
+	Tool           string   `yaml:"tool"`            // Tool name, or a pattern like mcp__jira__*
+	Description    string   `yaml:"description"`     // Input field shown as the description of a call
+	Code           []string `yaml:"code"`            // Input fields shown as code
+	Language       string   `yaml:"language"`        // Language the code fields are highlighted as
+	Paths          []string `yaml:"paths"`           // Input fields shown as file paths
+	Markdown       []string `yaml:"markdown"`        // Input fields rendered as Markdown
+	InputTemplate  string   `yaml:"input_template"`  // html/template for the input, replacing the fields above
+	OutputTemplate string   `yaml:"output_template"` // html/template for the result
+}
+
+// DeclarativeInput is the data of the input template of a declarative formatter
+type DeclarativeInput struct {
+	Input map[string]interface{}
+}
+
+// DeclarativeOutput is the data of the output template of a declarative formatter
+type DeclarativeOutput struct {
+	Input   map[string]interface{}
+	Content string      // Text of the result
+	Result  interface{} // Structured result of the call, if the log has one
+	JSON    interface{} // Content decoded, if it is JSON
+}
+
+// DeclarativeFormatter formats a tool as described by a DeclarativeSpec.
+type DeclarativeFormatter struct {
+	spec   DeclarativeSpec
+	input  *template.Template
+	output *template.Template
+}
+
+// declarativeFuncs are the functions templates of declarative formatters can call
+var declarativeFuncs = template.FuncMap{
+	"code":     formatCodeField,
+	"markdown": formatMarkdownField,
+	"path":     formatPathField,
+	"json":     FormatJSONTree,
+	"text": func(value interface{}) template.HTML {
+		return FormatText(fieldText(value))
+	},
+}
+
+// NewDeclarativeFormatter creates a formatter from a spec, parsing its templates
+func NewDeclarativeFormatter(spec DeclarativeSpec) (*DeclarativeFormatter, error) {
+	if spec.Tool == "" {
+		return nil, errors.New("missing tool name")
+	}
+	if _, err := path.Match(spec.Tool, ""); err != nil {
+		return nil, fmt.Errorf("invalid tool pattern %q: %w", spec.Tool, err)
+	}
+
+	f := &DeclarativeFormatter{spec: spec}
+	var err error
+	if spec.InputTemplate != "" {
+		if f.input, err = template.New("input").Funcs(declarativeFuncs).Parse(spec.InputTemplate); err != nil {
+			return nil, fmt.Errorf("invalid input template: %w", err)
+		}
+	}
+	if spec.OutputTemplate != "" {
+		if f.output, err = template.New("output").Funcs(declarativeFuncs).Parse(spec.OutputTemplate); err != nil {
+			return nil, fmt.Errorf("invalid output template: %w", err)
+		}
+	}
+	return f, nil
+}
+
+// Name returns the tool name or pattern of the formatter
+func (f *DeclarativeFormatter) Name() string {
+	return f.spec.Tool
+}
+
+// FormatInput formats the input with the input template, or else shows the path, code and
+// Markdown fields of the spec followed by the other fields as a JSON tree
+func (f *DeclarativeFormatter) FormatInput(data map[string]interface{}) (template.HTML, error) {
+	if f.input != nil {
+		return executeTemplate(f.input, DeclarativeInput{Input: data})
+	}
+
+	shown := make(map[string]bool)
+	var sb strings.Builder
+	sb.WriteString(`<div class="declarative-input">`)
+	writeFields := func(fields []string, format func(value interface{}) template.HTML) {
+		for _, field := range fields {
+			value, ok := data[field]
+			if !ok || shown[field] {
+				continue
+			}
+			shown[field] = true
+			sb.WriteString(`<div class="declarative-field">`)
+			sb.WriteString(fmt.Sprintf(`<div class="search-field-label">%s</div>`, html.EscapeString(field)))
+			sb.WriteString(string(format(value)))
+			sb.WriteString(`</div>`)
+		}
+	}
+	writeFields(f.spec.Paths, formatPathField)
+	writeFields(f.spec.Code, func(value interface{}) template.HTML {
+		return formatCodeField(f.spec.Language, value)
+	})
+	writeFields(f.spec.Markdown, formatMarkdownField)
+
+	rest := make(map[string]interface{})
+	for key, value := range data {
+		if !shown[key] {
+			rest[key] = value
+		}
+	}
+	if len(rest) > 0 {
+		sb.WriteString(string(FormatJSONTree(rest)))
+	}
+	sb.WriteString(`</div>`)
+	return template.HTML(sb.String()), nil
+}
+
+// FormatOutput formats a successful result with the output template. Failed calls, and all
+// results of specs without an output template, are shown like tools without a formatter.
+func (f *DeclarativeFormatter) FormatOutput(data map[string]interface{}, output *ToolOutput) (template.HTML, error) {
+	if f.output == nil || output == nil || !output.HasResult || output.IsError {
+		return FormatGenericOutput(output), nil
+	}
+
+	value, _ := ParseJSON(output.Content)
+	return executeTemplate(f.output, DeclarativeOutput{
+		Input:   data,
+		Content: output.Content,
+		Result:  output.ToolUseResult,
+		JSON:    value,
+	})
+}
+
+// ValidateInput accepts any input, since specs don't describe required fields
+func (f *DeclarativeFormatter) ValidateInput(data map[string]interface{}) error {
+	return nil
+}
+
+// GetDescription returns the input field the spec names as the description, falling back
+// to the description field like tools without a formatter
+func (f *DeclarativeFormatter) GetDescription(data map[string]interface{}) string {
+	field := f.spec.Description
+	if field == "" {
+		field = "description"
+	}
+	return fieldText(data[field])
+}
+
+// GetCompactView returns no compact view
+func (f *DeclarativeFormatter) GetCompactView(data map[string]interface{}) template.HTML {
+	return template.HTML("")
+}
+
+// LoadDeclarativeFormatters loads the formatters of the .yaml, .yml and .json files of a
+// directory, in file name order. Unknown fields are rejected so that typos aren't ignored.
+func LoadDeclarativeFormatters(dir string) ([]*DeclarativeFormatter, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, entry := range entries {
+		switch strings.ToLower(filepath.Ext(entry.Name())) {
+		case ".yaml", ".yml", ".json":
+			if !entry.IsDir() {
+				names = append(names, entry.Name())
+			}
+		}
+	}
+	sort.Strings(names)
+
+	formatters := make([]*DeclarativeFormatter, 0, len(names))
+	for _, name := range names {
+		formatter, err := loadDeclarativeFormatter(filepath.Join(dir, name))
+		if err != nil {
+			return nil, fmt.Errorf("loading formatter %s: %w", name, err)
+		}
+		formatters = append(formatters, formatter)
+	}
+	return formatters, nil
+}
+
+// loadDeclarativeFormatter loads the formatter of a spec file. JSON files are read as YAML,
+// which JSON is a subset of.
+func loadDeclarativeFormatter(filePath string) (*DeclarativeFormatter, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var spec DeclarativeSpec
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&spec); err != nil && err != io.EOF {
+		return nil, err
+	}
+	return NewDeclarativeFormatter(spec)
+}
+
+// LoadDir registers the declarative formatters of a directory. They take the place of
+// built-in formatters of the same tool name.
+func (r *FormatterRegistry) LoadDir(dir string) error {
+	formatters, err := LoadDeclarativeFormatters(dir)
+	if err != nil {
+		return err
+	}
+	for _, formatter := range formatters {
+		r.Register(formatter)
+	}
+	return nil
+}
+
+// executeTemplate runs a template of a declarative formatter
+func executeTemplate(tmpl *template.Template, data interface{}) (template.HTML, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return template.HTML(buf.String()), nil
+}
+
+// formatCodeField formats an input field as code highlighted in a language named like "python"
+func formatCodeField(language string, value interface{}) template.HTML {
+	return template.HTML(fmt.Sprintf(`<pre class="code-content">%s</pre>`, highlight.LanguageCode(language, fieldText(value))))
+}
+
+// formatMarkdownField renders an input field as Markdown
+func formatMarkdownField(value interface{}) template.HTML {
+	return template.HTML(`<div class="markdown">` + markdown.Render(fieldText(value)) + `</div>`)
+}
+
+// formatPathField formats an input field as a file path
+func formatPathField(value interface{}) template.HTML {
+	return template.HTML(fmt.Sprintf(`<span class="file-path">%s</span>`, html.EscapeString(fieldText(value))))
+}
+
+// fieldText returns the text of an input field: strings as they are and other values as
+// JSON
+func fieldText(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	default:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(encoded)
+	}
+}
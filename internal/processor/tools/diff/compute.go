@@ -4,132 +4,209 @@ import (
 	"strings"
 )
 
-// isMatchingLCSPosition checks if current positions match in the LCS
-func isMatchingLCSPosition(lcsIdx, oldIdx, newIdx int, lcs, oldLines, newLines []string) bool {
-	if lcsIdx >= len(lcs) || oldIdx >= len(oldLines) || newIdx >= len(newLines) {
-		return false
-	}
-	return oldLines[oldIdx] == lcs[lcsIdx] && newLines[newIdx] == lcs[lcsIdx]
-}
-
 // ComputeLineDiff computes a line-by-line diff between two strings.
+// Within each block of changes, removed lines come before added lines.
 func ComputeLineDiff(oldStr, newStr string) []DiffLine {
-	oldLines := strings.Split(oldStr, "\n")
-	newLines := strings.Split(newStr, "\n")
-
-	// If strings are identical, return all unchanged lines
-	if oldStr == newStr {
-		diff := make([]DiffLine, len(oldLines))
-		for i, line := range oldLines {
-			diff[i] = DiffLine{
-				Type:    LineUnchanged,
-				Content: line,
-				LineNum: i + 1,
-			}
-		}
-		return diff
+	return diffLines(strings.Split(oldStr, "\n"), strings.Split(newStr, "\n"))
+}
+
+// ComputeFileDiff is like ComputeLineDiff for whole file contents, where the newline
+// ending the last line doesn't start another line and an empty file has no lines.
+func ComputeFileDiff(oldContent, newContent string) []DiffLine {
+	return diffLines(splitFileLines(oldContent), splitFileLines(newContent))
+}
+
+// splitFileLines splits file content into lines
+func splitFileLines(content string) []string {
+	if content == "" {
+		return nil
 	}
+	return strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+}
 
-	// Simple diff: find longest common subsequence
-	lcs := longestCommonSubsequence(oldLines, newLines)
+// diffLines computes the diff of two line slices
+func diffLines(oldLines, newLines []string) []DiffLine {
+	m := newMyers(oldLines, newLines)
+	m.run()
 
-	// Build diff from LCS
-	diff := []DiffLine{}
+	diff := make([]DiffLine, 0, len(oldLines)+len(newLines))
 	oldIdx, newIdx := 0, 0
-	lcsIdx := 0
 	lineNum := 1
 
 	for oldIdx < len(oldLines) || newIdx < len(newLines) {
-		if isMatchingLCSPosition(lcsIdx, oldIdx, newIdx, lcs, oldLines, newLines) {
-			// Common line
-			diff = append(diff, DiffLine{
-				Type:    LineUnchanged,
-				Content: oldLines[oldIdx],
-				LineNum: lineNum,
-			})
+		line := DiffLine{LineNum: lineNum}
+
+		switch {
+		case oldIdx < len(oldLines) && m.removed[oldIdx]:
+			line.Type = LineRemoved
+			line.Content = oldLines[oldIdx]
+			line.OldLineNum = oldIdx + 1
 			oldIdx++
+		case newIdx < len(newLines) && m.added[newIdx]:
+			line.Type = LineAdded
+			line.Content = newLines[newIdx]
+			line.NewLineNum = newIdx + 1
 			newIdx++
-			lcsIdx++
-			lineNum++
-		} else if oldIdx < len(oldLines) && (lcsIdx >= len(lcs) || oldLines[oldIdx] != lcs[lcsIdx]) {
-			// Removed line
-			diff = append(diff, DiffLine{
-				Type:    LineRemoved,
-				Content: oldLines[oldIdx],
-				LineNum: lineNum,
-			})
+		default:
+			line.Type = LineUnchanged
+			line.Content = oldLines[oldIdx]
+			line.OldLineNum = oldIdx + 1
+			line.NewLineNum = newIdx + 1
 			oldIdx++
-			lineNum++
-		} else if newIdx < len(newLines) && (lcsIdx >= len(lcs) || newLines[newIdx] != lcs[lcsIdx]) {
-			// Added line
-			diff = append(diff, DiffLine{
-				Type:    LineAdded,
-				Content: newLines[newIdx],
-				LineNum: lineNum,
-			})
 			newIdx++
-			lineNum++
 		}
+
+		diff = append(diff, line)
+		lineNum++
 	}
 
 	return diff
 }
 
-// longestCommonSubsequence uses dynamic programming for optimal diff generation.
-func longestCommonSubsequence(a, b []string) []string {
-	m, n := len(a), len(b)
-	dp := make([][]int, m+1)
-	for i := range dp {
-		dp[i] = make([]int, n+1)
+// ComputeHunks computes the changes between two strings grouped into hunks with
+// contextLines unchanged lines around each change.
+func ComputeHunks(oldStr, newStr string, contextLines int) []Hunk {
+	return GroupHunks(ComputeLineDiff(oldStr, newStr), contextLines)
+}
+
+// GroupHunks groups diff lines into hunks. Changes separated by no more than
+// 2*contextLines unchanged lines share a hunk.
+func GroupHunks(lines []DiffLine, contextLines int) []Hunk {
+	if contextLines < 0 {
+		contextLines = 0
 	}
 
-	// Build the DP table
-	for i := 1; i <= m; i++ {
-		for j := 1; j <= n; j++ {
-			if a[i-1] == b[j-1] {
-				dp[i][j] = dp[i-1][j-1] + 1
-			} else {
-				dp[i][j] = max(dp[i-1][j], dp[i][j-1])
-			}
+	var hunks []Hunk
+	lastChange := -1 // Index of the last changed line of the open hunk
+
+	// closeHunk adds the trailing context of the open hunk
+	closeHunk := func() {
+		if len(hunks) == 0 {
+			return
+		}
+		end := lastChange + 1 + contextLines
+		if end > len(lines) {
+			end = len(lines)
 		}
+		hunk := &hunks[len(hunks)-1]
+		hunk.Lines = append(hunk.Lines, lines[lastChange+1:end]...)
+		hunk.count()
 	}
 
-	// Reconstruct the LCS
-	lcs := []string{}
-	i, j := m, n
-	for i > 0 && j > 0 {
-		if a[i-1] == b[j-1] {
-			lcs = append([]string{a[i-1]}, lcs...)
-			i--
-			j--
-		} else if dp[i-1][j] > dp[i][j-1] {
-			i--
+	for i, line := range lines {
+		if line.Type == LineUnchanged {
+			continue
+		}
+
+		if len(hunks) > 0 && i-lastChange-1 <= 2*contextLines {
+			// Join the open hunk, including the unchanged lines in between
+			hunk := &hunks[len(hunks)-1]
+			hunk.Lines = append(hunk.Lines, lines[lastChange+1:i+1]...)
 		} else {
-			j--
+			closeHunk()
+			start := i - contextLines
+			if start <= lastChange {
+				start = lastChange + 1
+			}
+			if start < 0 {
+				start = 0
+			}
+			oldBefore, newBefore := linesBefore(lines, start)
+			hunks = append(hunks, Hunk{
+				OldStart: oldBefore,
+				NewStart: newBefore,
+				Lines:    append([]DiffLine(nil), lines[start:i+1]...),
+			})
 		}
+		lastChange = i
 	}
+	closeHunk()
+
+	return hunks
+}
 
-	return lcs
+// linesBefore returns the number of old and new lines before lines[i]
+func linesBefore(lines []DiffLine, i int) (int, int) {
+	oldBefore, newBefore := -1, -1
+	for j := i - 1; j >= 0 && (oldBefore < 0 || newBefore < 0); j-- {
+		if oldBefore < 0 && lines[j].OldLineNum > 0 {
+			oldBefore = lines[j].OldLineNum
+		}
+		if newBefore < 0 && lines[j].NewLineNum > 0 {
+			newBefore = lines[j].NewLineNum
+		}
+	}
+	return max(oldBefore, 0), max(newBefore, 0)
 }
 
 // ComputeUnifiedDiff generates a unified diff format string.
+// Only hunks are written, callers add the ---/+++ file headers.
 func ComputeUnifiedDiff(oldStr, newStr string, contextLines int) string {
-	lines := ComputeLineDiff(oldStr, newStr)
+	var result strings.Builder
+
+	for _, hunk := range ComputeHunks(oldStr, newStr, contextLines) {
+		result.WriteString(hunk.Header())
+		result.WriteString("\n")
+		for _, line := range hunk.Lines {
+			result.WriteString(line.Type.Prefix())
+			result.WriteString(line.Content)
+			result.WriteString("\n")
+		}
+	}
+
+	return result.String()
+}
 
+// ComputeFilePatch generates the hunks of a patch between two file contents. Like diff,
+// it marks a last line without a newline with "\ No newline at end of file".
+// Only hunks are written, callers add the ---/+++ file headers.
+func ComputeFilePatch(oldContent, newContent string, contextLines int) string {
 	var result strings.Builder
 
-	for _, line := range lines {
-		result.WriteString(line.Type.Prefix())
-		result.WriteString(line.Content)
+	lines := diffLines(splitPatchLines(oldContent), splitPatchLines(newContent))
+	for _, hunk := range GroupHunks(lines, contextLines) {
+		result.WriteString(hunk.Header())
 		result.WriteString("\n")
+		for _, line := range hunk.Lines {
+			result.WriteString(line.Type.Prefix())
+			result.WriteString(line.Content)
+			if !strings.HasSuffix(line.Content, "\n") {
+				result.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
 	}
 
 	return result.String()
 }
 
-func max(a, b int) int {
-	if a > b {
-		return a
+// splitPatchLines splits file content into lines that keep their newline, so a last line
+// without one differs from the same line with one
+func splitPatchLines(content string) []string {
+	lines := strings.SplitAfter(content, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// PlaceAt numbers the diff of a snippet by the file lines it covers, given the file line the
+// snippet starts at. Unchanged and removed lines show their old line, added lines their new line.
+func PlaceAt(lines []DiffLine, startLine int) []DiffLine {
+	offset := startLine - 1
+	for i := range lines {
+		line := &lines[i]
+		if line.OldLineNum > 0 {
+			line.OldLineNum += offset
+		}
+		if line.NewLineNum > 0 {
+			line.NewLineNum += offset
+		}
+
+		if line.Type == LineAdded {
+			line.LineNum = line.NewLineNum
+		} else {
+			line.LineNum = line.OldLineNum
+		}
 	}
-	return b
+	return lines
 }
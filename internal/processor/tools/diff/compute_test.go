@@ -1,6 +1,10 @@
 package diff_test
 
 import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/brads3290/cclogviewer/internal/processor/tools/diff"
@@ -126,3 +130,282 @@ func TestLineType(t *testing.T) {
 		})
 	}
 }
+
+// lcsLength is a reference implementation used to check that diffs are minimal
+func lcsLength(a, b []string) int {
+	prev := make([]int, len(b)+1)
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		for j := 1; j <= len(b); j++ {
+			switch {
+			case a[i-1] == b[j-1]:
+				cur[j] = prev[j-1] + 1
+			case prev[j] > cur[j-1]:
+				cur[j] = prev[j]
+			default:
+				cur[j] = cur[j-1]
+			}
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+func TestComputeLineDiff_MinimalAndConsistent(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	alphabet := []string{"a", "b", "c", "d"}
+	randomText := func() []string {
+		lines := make([]string, rng.Intn(30))
+		for i := range lines {
+			lines[i] = alphabet[rng.Intn(len(alphabet))]
+		}
+		return lines
+	}
+
+	for i := 0; i < 500; i++ {
+		oldLines, newLines := randomText(), randomText()
+		oldStr, newStr := strings.Join(oldLines, "\n"), strings.Join(newLines, "\n")
+		oldLines, newLines = strings.Split(oldStr, "\n"), strings.Split(newStr, "\n")
+
+		var gotOld, gotNew []string
+		unchanged := 0
+		for _, line := range diff.ComputeLineDiff(oldStr, newStr) {
+			if line.Type != diff.LineAdded {
+				gotOld = append(gotOld, line.Content)
+			}
+			if line.Type != diff.LineRemoved {
+				gotNew = append(gotNew, line.Content)
+			}
+			if line.Type == diff.LineUnchanged {
+				unchanged++
+			}
+		}
+
+		if !reflect.DeepEqual(gotOld, oldLines) || !reflect.DeepEqual(gotNew, newLines) {
+			t.Fatalf("diff of %q and %q does not reproduce its inputs", oldStr, newStr)
+		}
+		if want := lcsLength(oldLines, newLines); unchanged != want {
+			t.Fatalf("diff of %q and %q keeps %d lines, want %d", oldStr, newStr, unchanged, want)
+		}
+	}
+}
+
+func TestComputeLineDiff_LineNumbers(t *testing.T) {
+	lines := diff.ComputeLineDiff("a\nb\nc", "a\nx\nc\nd")
+
+	got := make([][2]int, len(lines))
+	for i, line := range lines {
+		got[i] = [2]int{line.OldLineNum, line.NewLineNum}
+	}
+	want := [][2]int{{1, 1}, {2, 0}, {0, 2}, {3, 3}, {0, 4}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected line numbers %v, got %v", want, got)
+	}
+}
+
+func TestComputeUnifiedDiff(t *testing.T) {
+	var oldLines []string
+	for i := 1; i <= 20; i++ {
+		oldLines = append(oldLines, fmt.Sprintf("line%d", i))
+	}
+	newLines := append([]string(nil), oldLines...)
+	newLines[1] = "changed2"
+	newLines = append(newLines[:17], append([]string{"inserted"}, newLines[17:]...)...)
+
+	got := diff.ComputeUnifiedDiff(strings.Join(oldLines, "\n"), strings.Join(newLines, "\n"), 2)
+	want := "@@ -1,4 +1,4 @@\n" +
+		" line1\n-line2\n+changed2\n line3\n line4\n" +
+		"@@ -16,4 +16,5 @@\n" +
+		" line16\n line17\n+inserted\n line18\n line19\n"
+	if got != want {
+		t.Errorf("Unexpected unified diff:\n%s\nwant:\n%s", got, want)
+	}
+
+	if got := diff.ComputeUnifiedDiff("same", "same", 3); got != "" {
+		t.Errorf("Expected no hunks for identical input, got %q", got)
+	}
+}
+
+func TestGroupHunks_MergesNearbyChanges(t *testing.T) {
+	hunks := diff.ComputeHunks("a\nb\nc\nd\ne", "a\nB\nc\nD\ne", 1)
+	if len(hunks) != 1 {
+		t.Fatalf("Expected 1 hunk, got %d", len(hunks))
+	}
+	if header := hunks[0].Header(); header != "@@ -1,5 +1,5 @@" {
+		t.Errorf("Unexpected header %s", header)
+	}
+
+	// Pure insertion without context starts after the line before it
+	hunks = diff.ComputeHunks("a\nb", "a\nx\nb", 0)
+	if len(hunks) != 1 || hunks[0].Header() != "@@ -1,0 +2 @@" {
+		t.Errorf("Unexpected hunks %+v", hunks)
+	}
+}
+
+func TestFormatDiffHTML_FoldsUnchangedRuns(t *testing.T) {
+	var oldLines []string
+	for i := 1; i <= 30; i++ {
+		oldLines = append(oldLines, fmt.Sprintf("line%d", i))
+	}
+	newLines := append([]string(nil), oldLines...)
+	newLines[0] = "first"
+
+	html := string(diff.FormatDiffHTML(diff.ComputeLineDiff(strings.Join(oldLines, "\n"), strings.Join(newLines, "\n"))))
+
+	if !strings.Contains(html, "⋯ 26 unchanged lines") {
+		t.Errorf("Expected the unchanged run after the change to be folded, got %s", html)
+	}
+	if strings.Index(html, "line4") > strings.Index(html, "diff-fold") {
+		t.Errorf("Expected context lines before the fold")
+	}
+}
+
+func BenchmarkComputeLineDiff(b *testing.B) {
+	rng := rand.New(rand.NewSource(1))
+	var oldLines []string
+	for i := 0; i < 5000; i++ {
+		oldLines = append(oldLines, fmt.Sprintf("line %d", i))
+	}
+	newLines := append([]string(nil), oldLines...)
+	for i := 0; i < 200; i++ {
+		newLines[rng.Intn(len(newLines))] = fmt.Sprintf("changed %d", i)
+	}
+	oldStr, newStr := strings.Join(oldLines, "\n"), strings.Join(newLines, "\n")
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		diff.ComputeLineDiff(oldStr, newStr)
+	}
+}
+
+func TestHighlightChanges(t *testing.T) {
+	lines := diff.HighlightChanges(diff.ComputeLineDiff(
+		"keep\nresult := computeTotal(items)\nxyz",
+		"keep\nresult := computeSum(items)\nabc def ghi",
+	))
+
+	if len(lines) != 5 {
+		t.Fatalf("Expected 5 diff lines, got %d", len(lines))
+	}
+
+	want := []diff.Segment{
+		{Text: "result := ", Changed: false},
+		{Text: "computeTotal", Changed: true},
+		{Text: "(items)", Changed: false},
+	}
+	if !reflect.DeepEqual(lines[1].Segments, want) {
+		t.Errorf("Unexpected removed segments %+v", lines[1].Segments)
+	}
+	if lines[3].Segments[1].Text != "computeSum" || !lines[3].Segments[1].Changed {
+		t.Errorf("Unexpected added segments %+v", lines[3].Segments)
+	}
+
+	// Lines with nothing in common are highlighted as a whole
+	if lines[2].Segments != nil || lines[4].Segments != nil {
+		t.Errorf("Expected no segments for unrelated lines, got %+v and %+v", lines[2].Segments, lines[4].Segments)
+	}
+
+	html := diff.FormatDiffInline(lines[1])
+	if !strings.Contains(html, `result := <span class="segment-changed">computeTotal</span>(items)`) {
+		t.Errorf("Unexpected HTML %s", html)
+	}
+}
+
+func TestPlaceAt(t *testing.T) {
+	lines := diff.PlaceAt(diff.ComputeLineDiff("a\nb\nc", "a\nB\nc\nd"), 10)
+
+	var got []string
+	for _, line := range lines {
+		got = append(got, fmt.Sprintf("%d:%d:%d", line.LineNum, line.OldLineNum, line.NewLineNum))
+	}
+	expected := []string{"10:10:10", "11:11:0", "11:0:11", "12:12:12", "13:0:13"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("PlaceAt line numbers = %v, want %v", got, expected)
+	}
+}
+
+func TestComputeFileDiff_EmptyFile(t *testing.T) {
+	lines := diff.ComputeFileDiff("", "a\nb")
+	if len(lines) != 2 || lines[0].Type != diff.LineAdded || lines[1].Type != diff.LineAdded {
+		t.Errorf("ComputeFileDiff of a new file = %+v, want two added lines", lines)
+	}
+
+	if lines := diff.ComputeFileDiff("a", ""); len(lines) != 1 || lines[0].Type != diff.LineRemoved {
+		t.Errorf("ComputeFileDiff of an emptied file = %+v, want one removed line", lines)
+	}
+}
+
+func TestComputeFilePatch(t *testing.T) {
+	tests := []struct {
+		name       string
+		oldContent string
+		newContent string
+		expected   string
+	}{
+		{
+			name:       "new file",
+			oldContent: "",
+			newContent: "a\nb\n",
+			expected:   "@@ -0,0 +1,2 @@\n+a\n+b\n",
+		},
+		{
+			name:       "changed line",
+			oldContent: "a\nb\nc\n",
+			newContent: "a\nB\nc\n",
+			expected:   "@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n",
+		},
+		{
+			name:       "newline added at end",
+			oldContent: "a\nb",
+			newContent: "a\nb\n",
+			expected:   "@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+b\n",
+		},
+		{
+			name:       "unchanged",
+			oldContent: "a\n",
+			newContent: "a\n",
+			expected:   "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := diff.ComputeFilePatch(tt.oldContent, tt.newContent, 3); got != tt.expected {
+				t.Errorf("ComputeFilePatch() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFormatDiffHTML_SyntaxAndChanges(t *testing.T) {
+	lines := diff.HighlightChanges(diff.ComputeLineDiff("x := 1", "x := 2"))
+	lines = diff.HighlightSyntax(lines, "main.go")
+
+	html := string(diff.FormatDiffHTML(lines))
+	if !strings.Contains(html, `<span class="segment-changed"><span class="hl-number">1</span></span>`) {
+		t.Errorf("removed line should mark the changed number, got %s", html)
+	}
+	if !strings.Contains(html, `<span class="segment-changed"><span class="hl-number">2</span></span>`) {
+		t.Errorf("added line should mark the changed number, got %s", html)
+	}
+
+	plain := diff.HighlightSyntax(diff.ComputeLineDiff("a", "b"), "notes.txt")
+	if plain[0].Tokens != nil {
+		t.Errorf("lines of unknown languages should have no tokens, got %+v", plain[0].Tokens)
+	}
+}
+
+func TestFormatDiffHTML_TokensSplitBySegments(t *testing.T) {
+	line := diff.DiffLine{
+		Type:     diff.LineAdded,
+		Content:  "return abc",
+		Segments: []diff.Segment{{Text: "return a"}, {Text: "bc", Changed: true}},
+	}
+	line = diff.HighlightSyntax([]diff.DiffLine{line}, "main.go")[0]
+
+	html := diff.FormatDiffInline(line)
+	expected := `<span class="hl-keyword">return</span> a<span class="segment-changed">bc</span>`
+	if !strings.Contains(html, expected) {
+		t.Errorf("FormatDiffInline() = %s, want it to contain %s", html, expected)
+	}
+}
@@ -5,25 +5,19 @@ import (
 	"html"
 	"html/template"
 	"strings"
+
+	"github.com/brads3290/cclogviewer/internal/constants"
+	"github.com/brads3290/cclogviewer/internal/highlight"
 )
 
 // FormatDiffHTML formats diff lines as HTML with syntax highlighting.
+// Long runs of unchanged lines are folded into an expandable section.
 func FormatDiffHTML(lines []DiffLine) template.HTML {
 	var result strings.Builder
 
 	result.WriteString(`<div class="diff-content unified">`)
 	result.WriteString(`<div class="diff-code">`)
-
-	for _, line := range lines {
-		result.WriteString(fmt.Sprintf(`<div class="diff-line %s">`, line.Type.CSSClass()))
-		result.WriteString(fmt.Sprintf(`<span class="line-number">%3d</span>`, line.LineNum))
-		result.WriteString(fmt.Sprintf(`<span class="line-prefix">%s</span>`, line.Type.Prefix()))
-		result.WriteString(`<span class="line-content">`)
-		result.WriteString(html.EscapeString(line.Content))
-		result.WriteString(`</span>`)
-		result.WriteString(`</div>`)
-	}
-
+	writeFoldedLines(&result, lines)
 	result.WriteString(`</div>`)
 	result.WriteString(`</div>`)
 
@@ -37,6 +31,114 @@ func FormatDiffInline(line DiffLine) string {
 		line.Type.CSSClass(),
 		line.LineNum,
 		line.Type.Prefix(),
-		html.EscapeString(line.Content),
+		formatLineContent(line),
 	)
 }
+
+// formatLineContent escapes the content of a line, marking its syntax and changed segments
+func formatLineContent(line DiffLine) string {
+	if line.Tokens != nil {
+		return formatTokens(line)
+	}
+	if line.Segments == nil {
+		return html.EscapeString(line.Content)
+	}
+
+	var result strings.Builder
+	for _, segment := range line.Segments {
+		if segment.Changed {
+			result.WriteString(`<span class="segment-changed">`)
+			result.WriteString(html.EscapeString(segment.Text))
+			result.WriteString(`</span>`)
+		} else {
+			result.WriteString(html.EscapeString(segment.Text))
+		}
+	}
+	return result.String()
+}
+
+// formatTokens writes the syntax tokens of a line, splitting them where its changed
+// segments start and end
+func formatTokens(line DiffLine) string {
+	segments := line.Segments
+	if segments == nil {
+		segments = []Segment{{Text: line.Content}}
+	}
+
+	var result strings.Builder
+	seg, segLeft := 0, len(segments[0].Text)
+	for _, token := range line.Tokens {
+		for text := token.Text; text != ""; {
+			for segLeft == 0 && seg+1 < len(segments) {
+				seg++
+				segLeft = len(segments[seg].Text)
+			}
+
+			n := len(text)
+			if segLeft > 0 && segLeft < n {
+				n = segLeft
+			}
+			piece := highlight.Token{Class: token.Class, Text: text[:n]}
+			if segments[seg].Changed {
+				result.WriteString(`<span class="segment-changed">`)
+				highlight.WriteToken(&result, piece)
+				result.WriteString(`</span>`)
+			} else {
+				highlight.WriteToken(&result, piece)
+			}
+
+			text = text[n:]
+			segLeft -= n
+		}
+	}
+	return result.String()
+}
+
+// writeFoldedLines writes diff lines, folding unchanged runs that are long enough to keep
+// DiffContextLines of context on each side and still hide DiffFoldMinLines lines
+func writeFoldedLines(result *strings.Builder, lines []DiffLine) {
+	for i := 0; i < len(lines); {
+		if lines[i].Type != LineUnchanged {
+			result.WriteString(FormatDiffInline(lines[i]))
+			i++
+			continue
+		}
+
+		end := i
+		for end < len(lines) && lines[end].Type == LineUnchanged {
+			end++
+		}
+
+		// Context is only needed on the sides of the run that border a change
+		foldStart, foldEnd := i, end
+		if i > 0 {
+			foldStart += constants.DiffContextLines
+		}
+		if end < len(lines) {
+			foldEnd -= constants.DiffContextLines
+		}
+
+		if foldEnd-foldStart < constants.DiffFoldMinLines {
+			foldStart, foldEnd = end, end
+		}
+
+		for _, line := range lines[i:foldStart] {
+			result.WriteString(FormatDiffInline(line))
+		}
+		if foldStart < foldEnd {
+			result.WriteString(`<div class="diff-fold">`)
+			result.WriteString(fmt.Sprintf(`<div class="diff-fold-toggle">⋯ %d unchanged lines</div>`, foldEnd-foldStart))
+			result.WriteString(`<div class="diff-fold-lines" style="display: none;">`)
+			for _, line := range lines[foldStart:foldEnd] {
+				result.WriteString(FormatDiffInline(line))
+			}
+			result.WriteString(`</div>`)
+			result.WriteString(`</div>`)
+		}
+		for _, line := range lines[foldEnd:end] {
+			result.WriteString(FormatDiffInline(line))
+		}
+
+		i = end
+	}
+}
@@ -1,5 +1,11 @@
 package diff
 
+import (
+	"fmt"
+
+	"github.com/brads3290/cclogviewer/internal/highlight"
+)
+
 // LineType represents the type of change in a diff line.
 type LineType int
 
@@ -14,9 +20,19 @@ const (
 
 // DiffLine represents a line in a diff with its metadata.
 type DiffLine struct {
-	Type    LineType
-	Content string
-	LineNum int
+	Type       LineType
+	Content    string
+	LineNum    int               // Position of the line in the diff, or its file line once placed with PlaceAt
+	OldLineNum int               // Line number in the old text, 0 for added lines
+	NewLineNum int               // Line number in the new text, 0 for removed lines
+	Segments   []Segment         // Parts of a changed line, set by HighlightChanges; nil highlights the whole line
+	Tokens     []highlight.Token // Syntax of the line, set by HighlightSyntax; nil shows plain text
+}
+
+// Segment is a part of a changed line, marking whether that part itself changed.
+type Segment struct {
+	Text    string
+	Changed bool
 }
 
 // String returns the string representation of the line type
@@ -60,3 +76,45 @@ func (lt LineType) CSSClass() string {
 		return ""
 	}
 }
+
+// Hunk is a group of nearby changes together with their surrounding context lines.
+type Hunk struct {
+	OldStart int // First line of the hunk in the old text, or the line before it if OldLines is 0
+	OldLines int
+	NewStart int // First line of the hunk in the new text, or the line before it if NewLines is 0
+	NewLines int
+	Lines    []DiffLine
+}
+
+// Header returns the unified diff header of the hunk, e.g. "@@ -1,3 +1,4 @@"
+func (h Hunk) Header() string {
+	return fmt.Sprintf("@@ -%s +%s @@", hunkRange(h.OldStart, h.OldLines), hunkRange(h.NewStart, h.NewLines))
+}
+
+// count sets the line counts of the hunk from its lines, and the start lines if it has any.
+// Start lines of a side without lines are left unchanged.
+func (h *Hunk) count() {
+	h.OldLines, h.NewLines = 0, 0
+	for _, line := range h.Lines {
+		if line.Type != LineAdded {
+			if h.OldLines == 0 {
+				h.OldStart = line.OldLineNum
+			}
+			h.OldLines++
+		}
+		if line.Type != LineRemoved {
+			if h.NewLines == 0 {
+				h.NewStart = line.NewLineNum
+			}
+			h.NewLines++
+		}
+	}
+}
+
+// hunkRange formats a line range of a hunk header, leaving out a count of 1 like diff does
+func hunkRange(start, count int) string {
+	if count == 1 {
+		return fmt.Sprintf("%d", start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
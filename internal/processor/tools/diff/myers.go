@@ -0,0 +1,133 @@
+package diff
+
+// myers finds a shortest edit script between two sequences with the linear space variant of
+// Myers' algorithm. Lines are compared as integer IDs, so each line is hashed only once.
+type myers struct {
+	a, b    []int
+	removed []bool // removed[i] is true if a[i] is not part of the common subsequence
+	added   []bool // added[j] is true if b[j] is not part of the common subsequence
+	vf, vb  []int  // Furthest reaching paths of the forward and reverse searches
+}
+
+// newMyers prepares a diff of two line slices
+func newMyers(oldLines, newLines []string) *myers {
+	ids := make(map[string]int, len(oldLines))
+	intern := func(lines []string) []int {
+		result := make([]int, len(lines))
+		for i, line := range lines {
+			id, ok := ids[line]
+			if !ok {
+				id = len(ids)
+				ids[line] = id
+			}
+			result[i] = id
+		}
+		return result
+	}
+
+	size := len(oldLines) + len(newLines) + 4
+	return &myers{
+		a:       intern(oldLines),
+		b:       intern(newLines),
+		removed: make([]bool, len(oldLines)),
+		added:   make([]bool, len(newLines)),
+		vf:      make([]int, size),
+		vb:      make([]int, size),
+	}
+}
+
+// run marks every removed and added line
+func (m *myers) run() {
+	m.compare(0, len(m.a), 0, len(m.b))
+}
+
+// compare diffs a[aLo:aHi] with b[bLo:bHi] by splitting it at its middle snake
+func (m *myers) compare(aLo, aHi, bLo, bHi int) {
+	// Common prefixes and suffixes are never part of the edit script
+	for aLo < aHi && bLo < bHi && m.a[aLo] == m.b[bLo] {
+		aLo++
+		bLo++
+	}
+	for aLo < aHi && bLo < bHi && m.a[aHi-1] == m.b[bHi-1] {
+		aHi--
+		bHi--
+	}
+
+	switch {
+	case aLo == aHi:
+		for j := bLo; j < bHi; j++ {
+			m.added[j] = true
+		}
+	case bLo == bHi:
+		for i := aLo; i < aHi; i++ {
+			m.removed[i] = true
+		}
+	default:
+		// Both halves are strictly smaller: with the ends trimmed, at least two edits remain
+		x, y, u, v := m.middleSnake(aLo, aHi, bLo, bHi)
+		m.compare(aLo, x, bLo, y)
+		m.compare(u, aHi, v, bHi)
+	}
+}
+
+// middleSnake returns the start (x, y) and end (u, v) of the snake in the middle of a shortest
+// edit script, found by searching from both ends until the paths overlap
+func (m *myers) middleSnake(aLo, aHi, bLo, bHi int) (x, y, u, v int) {
+	n, mm := aHi-aLo, bHi-bLo
+	delta := n - mm
+	odd := delta%2 != 0
+	maxD := (n + mm + 1) / 2
+	off := maxD + 1
+
+	vf := m.vf[:2*maxD+3]
+	vb := m.vb[:2*maxD+3]
+	vf[off+1] = 0
+	vb[off+1] = 0
+
+	for d := 0; d <= maxD; d++ {
+		// Forward search, x measured from the start
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && vf[off+k-1] < vf[off+k+1]) {
+				x = vf[off+k+1]
+			} else {
+				x = vf[off+k-1] + 1
+			}
+			y := x - k
+			startX, startY := x, y
+			for x < n && y < mm && m.a[aLo+x] == m.b[bLo+y] {
+				x++
+				y++
+			}
+			vf[off+k] = x
+
+			if kr := delta - k; odd && kr >= -(d-1) && kr <= d-1 && x+vb[off+kr] >= n {
+				return aLo + startX, bLo + startY, aLo + x, bLo + y
+			}
+		}
+
+		// Reverse search, x measured from the end
+		for kr := -d; kr <= d; kr += 2 {
+			var x int
+			if kr == -d || (kr != d && vb[off+kr-1] < vb[off+kr+1]) {
+				x = vb[off+kr+1]
+			} else {
+				x = vb[off+kr-1] + 1
+			}
+			y := x - kr
+			startX, startY := x, y
+			for x < n && y < mm && m.a[aHi-1-x] == m.b[bHi-1-y] {
+				x++
+				y++
+			}
+			vb[off+kr] = x
+
+			if k := delta - kr; !odd && k >= -d && k <= d && x+vf[off+k] >= n {
+				return aHi - x, bHi - y, aHi - startX, bHi - startY
+			}
+		}
+	}
+
+	// Unreachable: the searches always meet by maxD
+	return aLo, bLo, aHi, bHi
+}
@@ -0,0 +1,126 @@
+package diff
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/brads3290/cclogviewer/internal/constants"
+)
+
+// HighlightChanges pairs the removed and added lines of each block of changes and splits
+// them into segments, marking the words that actually changed. Pairs that have too little
+// in common keep whole-line highlighting.
+func HighlightChanges(lines []DiffLine) []DiffLine {
+	for i := 0; i < len(lines); {
+		if lines[i].Type != LineRemoved {
+			i++
+			continue
+		}
+
+		// Removed lines come before added lines within a block
+		removedStart := i
+		for i < len(lines) && lines[i].Type == LineRemoved {
+			i++
+		}
+		addedStart := i
+		for i < len(lines) && lines[i].Type == LineAdded {
+			i++
+		}
+
+		pairs := min(addedStart-removedStart, i-addedStart)
+		for p := 0; p < pairs; p++ {
+			removed, added := &lines[removedStart+p], &lines[addedStart+p]
+			removed.Segments, added.Segments = wordDiff(removed.Content, added.Content)
+		}
+	}
+
+	return lines
+}
+
+// wordDiff returns the segments of an old and new line, or nil segments if the lines are
+// too different for word highlighting to help
+func wordDiff(oldLine, newLine string) ([]Segment, []Segment) {
+	oldWords, newWords := splitWords(oldLine), splitWords(newLine)
+
+	m := newMyers(oldWords, newWords)
+	m.run()
+
+	oldSegments, oldCommon := buildSegments(oldWords, m.removed)
+	newSegments, _ := buildSegments(newWords, m.added)
+
+	total := len(oldLine) + len(newLine)
+	if total == 0 || float64(2*oldCommon)/float64(total) < constants.IntraLineMinSimilarity {
+		return nil, nil
+	}
+
+	return oldSegments, newSegments
+}
+
+// buildSegments merges words into segments of changed and unchanged text and returns the
+// number of unchanged bytes
+func buildSegments(words []string, changed []bool) ([]Segment, int) {
+	var segments []Segment
+	common := 0
+
+	for i, word := range words {
+		// Whitespace between two changed words is shown as changed too
+		isChanged := changed[i] || (isSpace(word) && i > 0 && i < len(words)-1 && changed[i-1] && changed[i+1])
+		if !changed[i] {
+			common += len(word)
+		}
+
+		if n := len(segments); n > 0 && segments[n-1].Changed == isChanged {
+			segments[n-1].Text += word
+		} else {
+			segments = append(segments, Segment{Text: word, Changed: isChanged})
+		}
+	}
+
+	return segments, common
+}
+
+// splitWords splits a line into words, runs of whitespace and single punctuation characters
+func splitWords(line string) []string {
+	var words []string
+	var current strings.Builder
+	currentKind := 0
+
+	for _, r := range line {
+		kind := runeKind(r)
+		if current.Len() > 0 && (kind != currentKind || kind == kindPunct) {
+			words = append(words, current.String())
+			current.Reset()
+		}
+		current.WriteRune(r)
+		currentKind = kind
+	}
+	if current.Len() > 0 {
+		words = append(words, current.String())
+	}
+
+	return words
+}
+
+// Rune kinds used to split words
+const (
+	kindWord = iota + 1
+	kindSpace
+	kindPunct
+)
+
+// runeKind classifies a rune for splitting words
+func runeKind(r rune) int {
+	switch {
+	case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_':
+		return kindWord
+	case unicode.IsSpace(r):
+		return kindSpace
+	default:
+		return kindPunct
+	}
+}
+
+// isSpace reports whether a word is a run of whitespace
+func isSpace(word string) bool {
+	return strings.TrimSpace(word) == ""
+}
@@ -0,0 +1,37 @@
+package diff
+
+import "github.com/brads3290/cclogviewer/internal/highlight"
+
+// HighlightSyntax sets the syntax tokens of each line for the language of the file at path.
+// The old and new sides are highlighted separately, so constructs spanning lines such as
+// block comments carry over correctly on each side. Files of unknown languages are left plain.
+func HighlightSyntax(lines []DiffLine, path string) []DiffLine {
+	return highlightSides(lines, highlight.ForFile(path), highlight.ForFile(path))
+}
+
+// HighlightLanguage is like HighlightSyntax for code in a language named like "python",
+// such as a notebook cell
+func HighlightLanguage(lines []DiffLine, language string) []DiffLine {
+	return highlightSides(lines, highlight.ForLanguage(language), highlight.ForLanguage(language))
+}
+
+// highlightSides sets the syntax tokens of each line with a highlighter for each side
+func highlightSides(lines []DiffLine, oldSide, newSide *highlight.Highlighter) []DiffLine {
+	if oldSide == nil {
+		return lines
+	}
+
+	for i := range lines {
+		line := &lines[i]
+		switch line.Type {
+		case LineRemoved:
+			line.Tokens = oldSide.Line(line.Content)
+		case LineAdded:
+			line.Tokens = newSide.Line(line.Content)
+		default:
+			line.Tokens = oldSide.Line(line.Content)
+			newSide.Line(line.Content)
+		}
+	}
+	return lines
+}
@@ -2,8 +2,8 @@ package tools
 
 import (
 	"fmt"
-	"html"
 	"html/template"
+	"path"
 	"strings"
 	"sync"
 )
@@ -12,7 +12,7 @@ import (
 type ToolFormatter interface {
 	Name() string
 	FormatInput(data map[string]interface{}) (template.HTML, error)
-	FormatOutput(output interface{}) (template.HTML, error)
+	FormatOutput(data map[string]interface{}, output *ToolOutput) (template.HTML, error)
 	ValidateInput(data map[string]interface{}) error
 	GetDescription(data map[string]interface{}) string
 	GetCompactView(data map[string]interface{}) template.HTML
@@ -24,9 +24,23 @@ type BashFormatter interface {
 	FormatInputWithCWD(data map[string]interface{}, cwd string) (template.HTML, error)
 }
 
+// EditFormatter extends ToolFormatter with the file lines edits start at.
+type EditFormatter interface {
+	ToolFormatter
+	FormatInputAtLines(data map[string]interface{}, lines []int) (template.HTML, error)
+}
+
+// InlineFormatter extends ToolFormatter for tools whose output shows the whole call, which is
+// then displayed open instead of behind a collapsible header.
+type InlineFormatter interface {
+	ToolFormatter
+	IsInline() bool
+}
+
 // FormatterRegistry manages tool-specific formatters.
 type FormatterRegistry struct {
 	formatters map[string]ToolFormatter
+	patterns   []ToolFormatter // Formatters named by a pattern like mcp__jira__*, in the order registered
 	mu         sync.RWMutex
 }
 
@@ -37,18 +51,46 @@ func NewFormatterRegistry() *FormatterRegistry {
 	}
 }
 
-// Register adds a formatter to the registry
+// Register adds a formatter to the registry. Formatters whose name is a pattern, like
+// mcp__jira__*, format the tools it matches that have no formatter of their own. A
+// formatter registered later takes precedence: it replaces one of the same name, and its
+// pattern is tried before those registered before it.
 func (r *FormatterRegistry) Register(formatter ToolFormatter) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
+	if isToolPattern(formatter.Name()) {
+		r.patterns = append(r.patterns, formatter)
+		return
+	}
 	r.formatters[formatter.Name()] = formatter
 }
 
+// lookup returns the formatter of a tool: the one registered for its name, or else the
+// last one registered whose pattern matches it
+func (r *FormatterRegistry) lookup(toolName string) (ToolFormatter, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if formatter, exists := r.formatters[toolName]; exists {
+		return formatter, true
+	}
+	for i := len(r.patterns) - 1; i >= 0; i-- {
+		formatter := r.patterns[i]
+		if matched, _ := path.Match(formatter.Name(), toolName); matched {
+			return formatter, true
+		}
+	}
+	return nil, false
+}
+
+// isToolPattern reports whether a formatter name is a pattern matching several tools
+func isToolPattern(name string) bool {
+	return strings.ContainsAny(name, "*?[")
+}
+
 // Format formats tool input using the appropriate formatter
 func (r *FormatterRegistry) Format(toolName string, data map[string]interface{}) (template.HTML, error) {
-	r.mu.RLock()
-	formatter, exists := r.formatters[toolName]
-	r.mu.RUnlock()
+	formatter, exists := r.lookup(toolName)
 
 	if !exists {
 		return r.formatGeneric(toolName, data)
@@ -61,11 +103,33 @@ func (r *FormatterRegistry) Format(toolName string, data map[string]interface{})
 	return formatter.FormatInput(data)
 }
 
+// FormatOutput formats the result of a tool call using the appropriate formatter. Tools
+// without a formatter show the result as text.
+func (r *FormatterRegistry) FormatOutput(toolName string, data map[string]interface{}, output *ToolOutput) (template.HTML, error) {
+	formatter, exists := r.lookup(toolName)
+
+	if !exists {
+		return FormatGenericOutput(output), nil
+	}
+
+	return formatter.FormatOutput(data, output)
+}
+
+// IsInline reports whether the output of a tool shows the whole call
+func (r *FormatterRegistry) IsInline(toolName string) bool {
+	formatter, exists := r.lookup(toolName)
+
+	if !exists {
+		return false
+	}
+
+	inlineFormatter, ok := formatter.(InlineFormatter)
+	return ok && inlineFormatter.IsInline()
+}
+
 // GetDescription gets the tool description using the appropriate formatter
 func (r *FormatterRegistry) GetDescription(toolName string, data map[string]interface{}) string {
-	r.mu.RLock()
-	formatter, exists := r.formatters[toolName]
-	r.mu.RUnlock()
+	formatter, exists := r.lookup(toolName)
 
 	if !exists {
 		// Default to extracting description field
@@ -80,9 +144,7 @@ func (r *FormatterRegistry) GetDescription(toolName string, data map[string]inte
 
 // GetCompactView gets the compact view for a tool
 func (r *FormatterRegistry) GetCompactView(toolName string, data map[string]interface{}) template.HTML {
-	r.mu.RLock()
-	formatter, exists := r.formatters[toolName]
-	r.mu.RUnlock()
+	formatter, exists := r.lookup(toolName)
 
 	if !exists {
 		return template.HTML("")
@@ -93,9 +155,7 @@ func (r *FormatterRegistry) GetCompactView(toolName string, data map[string]inte
 
 // FormatWithCWD formats tool input with current working directory (for Bash tool)
 func (r *FormatterRegistry) FormatWithCWD(toolName string, data map[string]interface{}, cwd string) (template.HTML, error) {
-	r.mu.RLock()
-	formatter, exists := r.formatters[toolName]
-	r.mu.RUnlock()
+	formatter, exists := r.lookup(toolName)
 
 	if !exists {
 		return r.formatGeneric(toolName, data)
@@ -113,44 +173,31 @@ func (r *FormatterRegistry) FormatWithCWD(toolName string, data map[string]inter
 	return r.Format(toolName, data)
 }
 
-// formatGeneric formats tools that don't have specific formatters
-func (r *FormatterRegistry) formatGeneric(toolName string, data map[string]interface{}) (template.HTML, error) {
-	// Build a JSON-like representation
-	var sb strings.Builder
-	sb.WriteString(`<div class="tool-input"><pre>`)
-
-	// Format the data as key-value pairs
-	first := true
-	sb.WriteString("{")
-	for key, value := range data {
-		if !first {
-			sb.WriteString(",")
-		}
-		first = false
-		sb.WriteString("\n  ")
-		sb.WriteString(fmt.Sprintf(`"%s": `, key))
-
-		// Format the value based on type
-		switch v := value.(type) {
-		case string:
-			sb.WriteString(fmt.Sprintf(`"%s"`, html.EscapeString(v)))
-		case float64:
-			sb.WriteString(fmt.Sprintf("%v", v))
-		case bool:
-			sb.WriteString(fmt.Sprintf("%v", v))
-		case []interface{}:
-			sb.WriteString("[...]")
-		case map[string]interface{}:
-			sb.WriteString("{...}")
-		default:
-			sb.WriteString(fmt.Sprintf("%v", v))
-		}
+// FormatWithEditLines formats tool input with the file lines its edits start at (for Edit and MultiEdit tools)
+func (r *FormatterRegistry) FormatWithEditLines(toolName string, data map[string]interface{}, lines []int) (template.HTML, error) {
+	formatter, exists := r.lookup(toolName)
+
+	if !exists {
+		return r.formatGeneric(toolName, data)
 	}
-	if len(data) > 0 {
-		sb.WriteString("\n")
+
+	if editFormatter, ok := formatter.(EditFormatter); ok {
+		if err := formatter.ValidateInput(data); err != nil {
+			return "", fmt.Errorf("invalid input for %s: %w", toolName, err)
+		}
+		return editFormatter.FormatInputAtLines(data, lines)
 	}
-	sb.WriteString("}")
 
-	sb.WriteString(`</pre></div>`)
-	return template.HTML(sb.String()), nil
+	// Fallback to regular formatting
+	return r.Format(toolName, data)
+}
+
+// formatGeneric formats tools that don't have specific formatters
+func (r *FormatterRegistry) formatGeneric(toolName string, data map[string]interface{}) (template.HTML, error) {
+	return FormatGenericInput(data), nil
+}
+
+// FormatGenericInput shows a tool input as a JSON tree, like for tools without a formatter
+func FormatGenericInput(data map[string]interface{}) template.HTML {
+	return `<div class="tool-input">` + FormatJSONTree(data) + `</div>`
 }
@@ -1,6 +1,8 @@
 package tools_test
 
 import (
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -89,6 +91,39 @@ func TestEditFormatter(t *testing.T) {
 	}
 }
 
+func TestEditFormatter_HighlightsSyntax(t *testing.T) {
+	html, err := formatters.NewEditFormatter().FormatInput(map[string]interface{}{
+		"file_path":  "/test/file.go",
+		"old_string": "return 1",
+		"new_string": "return 2",
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if !contains(string(html), `<span class="hl-keyword">return</span>`) {
+		t.Errorf("Expected highlighted keyword in diff, got %s", html)
+	}
+}
+
+func TestWriteFormatter_HighlightsSyntax(t *testing.T) {
+	html, err := formatters.NewWriteFormatter().FormatInput(map[string]interface{}{
+		"file_path": "/test/config.json",
+		"content":   `{"debug": true}`,
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	htmlStr := string(html)
+	if !contains(htmlStr, `<span class="hl-key">&#34;debug&#34;</span>`) {
+		t.Errorf("Expected highlighted key, got %s", htmlStr)
+	}
+	if !contains(htmlStr, `<span class="hl-literal">true</span>`) {
+		t.Errorf("Expected highlighted literal, got %s", htmlStr)
+	}
+}
+
 func TestMultiEditFormatter(t *testing.T) {
 	formatter := formatters.NewMultiEditFormatter()
 
@@ -167,6 +202,651 @@ func TestBashFormatter(t *testing.T) {
 	}
 }
 
+func TestBashFormatter_FormatOutput(t *testing.T) {
+	formatter := formatters.NewBashFormatter()
+	data := map[string]interface{}{"command": "ls"}
+
+	if !formatter.IsInline() {
+		t.Error("Expected Bash calls to be shown inline")
+	}
+
+	html, err := formatter.FormatOutput(data, &tools.ToolOutput{
+		HasResult: true,
+		Content:   "a.txt\n\x1b[31mb.txt\x1b[0m",
+		CWD:       "/home/user",
+	})
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	htmlStr := string(html)
+	if !contains(htmlStr, `<span class="bash-command">ls</span>`) {
+		t.Error("Expected command in output")
+	}
+	if !contains(htmlStr, "/home/user") {
+		t.Error("Expected CWD in output")
+	}
+	if !contains(htmlStr, "a.txt<br>") || contains(htmlStr, "\x1b") {
+		t.Errorf("Expected ANSI-converted output lines, got %s", htmlStr)
+	}
+
+	// Long output is collapsed behind a More link
+	long := strings.Repeat("line\n", 30)
+	html, _ = formatter.FormatOutput(data, &tools.ToolOutput{HasResult: true, Content: long})
+	if !contains(string(html), "bash-more-content") {
+		t.Error("Expected long output to be collapsible")
+	}
+}
+
+func TestReadFormatter_FormatOutput(t *testing.T) {
+	formatter := formatters.NewReadFormatter()
+	data := map[string]interface{}{"file_path": "/src/app.py"}
+
+	html, err := formatter.FormatOutput(data, &tools.ToolOutput{
+		HasResult: true,
+		Content:   "     1→def main():\n     2→    return None",
+	})
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	htmlStr := string(html)
+	if !contains(htmlStr, `<span class="line-number">1</span>`) {
+		t.Error("Expected line numbers in output")
+	}
+	if !contains(htmlStr, `<span class="hl-keyword">def</span> <span class="hl-function">main</span>`) {
+		t.Errorf("Expected highlighted file content, got %s", htmlStr)
+	}
+
+	html, _ = formatter.FormatOutput(data, &tools.ToolOutput{})
+	if html != "" {
+		t.Error("Expected no output without a result")
+	}
+}
+
+func TestFormatterRegistry_FormatOutput(t *testing.T) {
+	registry := tools.NewFormatterRegistry()
+	registry.Register(formatters.NewEditFormatter())
+	registry.Register(formatters.NewBashFormatter())
+
+	editData := map[string]interface{}{
+		"file_path":  "/test/file.go",
+		"old_string": "a",
+		"new_string": "b",
+	}
+
+	// Successful edits are shown by the diff of the input alone
+	html, err := registry.FormatOutput("Edit", editData, &tools.ToolOutput{HasResult: true, Content: "The file was updated"})
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if html != "" {
+		t.Errorf("Expected no output for a successful edit, got %s", html)
+	}
+
+	// Failed edits show the error
+	html, _ = registry.FormatOutput("Edit", editData, &tools.ToolOutput{HasResult: true, Content: "String not found", IsError: true})
+	if !contains(string(html), "String not found") || !contains(string(html), "result-header") {
+		t.Errorf("Expected the error in a result section, got %s", html)
+	}
+
+	// Tools without a formatter show the result as text
+	html, _ = registry.FormatOutput("Unknown", nil, &tools.ToolOutput{HasResult: true, Content: "<b>done</b>\nok"})
+	if !contains(string(html), "&lt;b&gt;done&lt;/b&gt;<br>ok") {
+		t.Errorf("Expected escaped text result, got %s", html)
+	}
+	html, _ = registry.FormatOutput("Unknown", nil, &tools.ToolOutput{})
+	if html != "" {
+		t.Error("Expected no output without a result")
+	}
+
+	if !registry.IsInline("Bash") || registry.IsInline("Edit") || registry.IsInline("Unknown") {
+		t.Error("Expected only Bash to be inline")
+	}
+}
+
+func TestFormatterRegistry_Generic(t *testing.T) {
+	registry := tools.NewFormatterRegistry()
+	data := map[string]interface{}{
+		"zeta":   "last",
+		"alpha":  map[string]interface{}{"nested": []interface{}{1.0, true, nil}},
+		"middle": "<b>",
+	}
+
+	html, err := registry.Format("mcp__docs__search", data)
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	htmlStr := string(html)
+	alpha, middle, zeta := strings.Index(htmlStr, "&#34;alpha"), strings.Index(htmlStr, "&#34;middle"), strings.Index(htmlStr, "&#34;zeta")
+	if alpha < 0 || alpha > middle || middle > zeta {
+		t.Errorf("Expected keys in sorted order, got %s", htmlStr)
+	}
+	if !contains(htmlStr, `<span class="hl-number">1</span>`) || !contains(htmlStr, `<span class="hl-literal">null</span>`) {
+		t.Errorf("Expected nested values to be shown, got %s", htmlStr)
+	}
+	if contains(htmlStr, "<b>") || contains(htmlStr, "{...}") {
+		t.Errorf("Expected escaped and expanded values, got %s", htmlStr)
+	}
+
+	again, _ := registry.Format("mcp__docs__search", data)
+	if again != html {
+		t.Error("Expected the same tree on every render")
+	}
+}
+
+func TestFormatGenericOutput_JSON(t *testing.T) {
+	html := tools.FormatGenericOutput(&tools.ToolOutput{HasResult: true, Content: `{"id": 12345678901234567890, "ok": true}`})
+	if !contains(string(html), `class="json-tree"`) || !contains(string(html), `<span class="hl-number">12345678901234567890</span>`) {
+		t.Errorf("Expected JSON result as a tree keeping numbers as written, got %s", html)
+	}
+
+	html = tools.FormatGenericOutput(&tools.ToolOutput{HasResult: true, Content: "[not json]"})
+	if contains(string(html), "json-tree") {
+		t.Error("Expected text that isn't JSON to be shown as text")
+	}
+
+	html = tools.FormatGenericOutput(&tools.ToolOutput{HasResult: true, ToolUseResult: []interface{}{map[string]interface{}{"type": "text"}}})
+	if !contains(string(html), `class="json-tree"`) {
+		t.Errorf("Expected structured result as a tree, got %s", html)
+	}
+}
+
+func TestDeclarativeFormatter(t *testing.T) {
+	formatter, err := tools.NewDeclarativeFormatter(tools.DeclarativeSpec{
+		Tool:        "mcp__warehouse__query",
+		Description: "title",
+		Code:        []string{"script"},
+		Language:    "python",
+		Paths:       []string{"export_to"},
+		Markdown:    []string{"notes"},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	data := map[string]interface{}{
+		"title":     "Weekly signups",
+		"script":    "import pandas",
+		"export_to": "/tmp/out.csv",
+		"notes":     "Only **active** users",
+		"limit":     100.0,
+	}
+	html, err := formatter.FormatInput(data)
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	htmlStr := string(html)
+	if !contains(htmlStr, `<span class="file-path">/tmp/out.csv</span>`) || !contains(htmlStr, "<strong>active</strong>") {
+		t.Errorf("Expected path and Markdown fields, got %s", htmlStr)
+	}
+	if !contains(htmlStr, `<pre class="code-content"><span class="hl-keyword">import</span>`) {
+		t.Errorf("Expected code field highlighted in its language, got %s", htmlStr)
+	}
+	if !contains(htmlStr, `<span class="hl-number">100</span>`) {
+		t.Errorf("Expected other fields as a JSON tree, got %s", htmlStr)
+	}
+	if desc := formatter.GetDescription(data); desc != "Weekly signups" {
+		t.Errorf("Unexpected description %q", desc)
+	}
+
+	html, _ = formatter.FormatOutput(data, &tools.ToolOutput{HasResult: true, Content: "3 rows"})
+	if !contains(string(html), "3 rows") {
+		t.Error("Expected results without an output template to be shown as text")
+	}
+
+	formatter, err = tools.NewDeclarativeFormatter(tools.DeclarativeSpec{
+		Tool:           "mcp__warehouse__*",
+		InputTemplate:  `<b>{{.Input.table}}</b>`,
+		OutputTemplate: `{{range .JSON.rows}}<i>{{.}}</i>{{end}}`,
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	html, _ = formatter.FormatInput(map[string]interface{}{"table": "<users>"})
+	if string(html) != "<b>&lt;users&gt;</b>" {
+		t.Errorf("Expected escaped input template, got %s", html)
+	}
+	html, _ = formatter.FormatOutput(nil, &tools.ToolOutput{HasResult: true, Content: `{"rows": ["a", "b"]}`})
+	if string(html) != "<i>a</i><i>b</i>" {
+		t.Errorf("Expected output template over the JSON result, got %s", html)
+	}
+
+	if _, err := tools.NewDeclarativeFormatter(tools.DeclarativeSpec{Tool: "x", InputTemplate: "{{.Input"}); err == nil {
+		t.Error("Expected an error for an invalid template")
+	}
+}
+
+func TestFormatterRegistry_LoadDir(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"jira.yaml":   "tool: mcp__jira__*\ndescription: summary\nmarkdown: [body]\n",
+		"issue.json":  `{"tool": "mcp__jira__get_issue", "description": "key"}`,
+		"zz-jira.yml": "tool: mcp__jira__create_*\ndescription: title\n",
+		"notes.txt":   "not a formatter",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	registry := tools.NewFormatterRegistry()
+	if err := registry.LoadDir(dir); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	data := map[string]interface{}{"summary": "Crash on start", "key": "APP-1", "body": "# Steps", "title": "Crash"}
+	if desc := registry.GetDescription("mcp__jira__search", data); desc != "Crash on start" {
+		t.Errorf("Expected the pattern formatter, got description %q", desc)
+	}
+	if desc := registry.GetDescription("mcp__jira__create_issue", data); desc != "Crash" {
+		t.Errorf("Expected the pattern of the later file, got description %q", desc)
+	}
+	if desc := registry.GetDescription("mcp__jira__get_issue", data); desc != "APP-1" {
+		t.Errorf("Expected the formatter named for the tool over the pattern, got description %q", desc)
+	}
+	if desc := registry.GetDescription("mcp__github__create_issue", data); desc != "" {
+		t.Errorf("Expected no formatter for other tools, got description %q", desc)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "typo.yml"), []byte("tool: X\ndescripton: y\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := tools.NewFormatterRegistry().LoadDir(dir); err == nil || !contains(err.Error(), "typo.yml") {
+		t.Errorf("Expected an error naming the spec with an unknown field, got %v", err)
+	}
+}
+
+func TestSplitMCPToolName(t *testing.T) {
+	tests := []struct {
+		name   string
+		server string
+		tool   string
+		ok     bool
+	}{
+		{"mcp__github__create_issue", "github", "create_issue", true},
+		{"mcp__claude_ai_Linear__list_issues", "claude_ai_Linear", "list_issues", true},
+		{"mcp__github", "", "", false},
+		{"Bash", "", "", false},
+	}
+	for _, tt := range tests {
+		server, tool, ok := tools.SplitMCPToolName(tt.name)
+		if server != tt.server || tool != tt.tool || ok != tt.ok {
+			t.Errorf("SplitMCPToolName(%q) = %q, %q, %v", tt.name, server, tool, ok)
+		}
+	}
+}
+
+func TestGrepFormatter(t *testing.T) {
+	formatter := formatters.NewGrepFormatter()
+
+	if err := formatter.ValidateInput(map[string]interface{}{}); err == nil {
+		t.Error("Expected validation error for missing pattern")
+	}
+
+	data := map[string]interface{}{
+		"pattern":     "func \\w+",
+		"path":        "/repo",
+		"glob":        "*.go",
+		"output_mode": "content",
+		"-i":          true,
+	}
+
+	html, err := formatter.FormatInput(data)
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	htmlStr := string(html)
+	for _, want := range []string{`func \w+`, "/repo", "*.go", "content", "case insensitive"} {
+		if !contains(htmlStr, want) {
+			t.Errorf("Expected %q in header, got %s", want, htmlStr)
+		}
+	}
+
+	if desc := formatter.GetDescription(data); desc != `func \w+ in /repo (*.go)` {
+		t.Errorf("Unexpected description %q", desc)
+	}
+}
+
+func TestGrepFormatter_FormatOutput(t *testing.T) {
+	formatter := formatters.NewGrepFormatter()
+
+	t.Run("content", func(t *testing.T) {
+		data := map[string]interface{}{"pattern": "Fo+", "path": "/repo", "output_mode": "content", "-i": true}
+		content := "/repo/a.go:3:func foo() {}\n/repo/a.go-4-\treturn\n--\n/repo/b.go:10:var FOO = 1"
+
+		html, err := formatter.FormatOutput(data, &tools.ToolOutput{HasResult: true, Content: content})
+		if err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+		htmlStr := string(html)
+		if !contains(htmlStr, "2 matches in 2 files") {
+			t.Errorf("Expected match count, got %s", htmlStr)
+		}
+		if !contains(htmlStr, `func <mark class="search-match">foo</mark>() {}`) {
+			t.Errorf("Expected highlighted match, got %s", htmlStr)
+		}
+		if !contains(htmlStr, `<mark class="search-match">FOO</mark>`) {
+			t.Error("Expected case insensitive match to be highlighted")
+		}
+		if !contains(htmlStr, `<span class="line-number">10</span>`) {
+			t.Error("Expected line numbers")
+		}
+		if !contains(htmlStr, `href="file:///repo/a.go"`) || !contains(htmlStr, `>a.go</a>`) {
+			t.Errorf("Expected file link relative to the searched path, got %s", htmlStr)
+		}
+		if !contains(htmlStr, `class="grep-line context"`) {
+			t.Error("Expected context line")
+		}
+	})
+
+	t.Run("dated path", func(t *testing.T) {
+		data := map[string]interface{}{"pattern": "TODO", "path": "/repo", "output_mode": "content"}
+		content := "/repo/docs/2024-01-15-notes.md-2-context\n/repo/docs/2024-01-15-notes.md:3:TODO fix"
+
+		html, _ := formatter.FormatOutput(data, &tools.ToolOutput{HasResult: true, Content: content})
+		htmlStr := string(html)
+		if !contains(htmlStr, "1 match in 1 file") {
+			t.Errorf("Expected one match in one file, got %s", htmlStr)
+		}
+		if !contains(htmlStr, `>docs/2024-01-15-notes.md</a>`) || !contains(htmlStr, `<span class="line-number">3</span>`) {
+			t.Errorf("Expected the whole path and line 3, got %s", htmlStr)
+		}
+	})
+
+	t.Run("relative dated path", func(t *testing.T) {
+		data := map[string]interface{}{"pattern": "TODO", "output_mode": "content"}
+		content := "2024-01-15-notes.md-2-context\n2024-01-15-notes.md:3:TODO fix"
+
+		html, _ := formatter.FormatOutput(data, &tools.ToolOutput{HasResult: true, Content: content})
+		htmlStr := string(html)
+		if !contains(htmlStr, "1 match in 1 file") {
+			t.Errorf("Expected one match in one file, got %s", htmlStr)
+		}
+		if !contains(htmlStr, `>2024-01-15-notes.md</span>`) || !contains(htmlStr, `<span class="line-number">3</span>`) {
+			t.Errorf("Expected the whole path and line 3, got %s", htmlStr)
+		}
+	})
+
+	t.Run("single file", func(t *testing.T) {
+		data := map[string]interface{}{"pattern": "foo", "path": "/repo/a.go", "output_mode": "content"}
+
+		html, _ := formatter.FormatOutput(data, &tools.ToolOutput{HasResult: true, Content: "3:func foo() {}"})
+		htmlStr := string(html)
+		if !contains(htmlStr, `href="file:///repo/a.go"`) || !contains(htmlStr, `>a.go</a>`) {
+			t.Errorf("Expected a link named after the file, got %s", htmlStr)
+		}
+	})
+
+	t.Run("files", func(t *testing.T) {
+		data := map[string]interface{}{"pattern": "foo"}
+		html, _ := formatter.FormatOutput(data, &tools.ToolOutput{
+			HasResult: true,
+			Content:   "Found 2 files\n/repo/a.go\n/repo/b.go",
+			CWD:       "/repo",
+		})
+		htmlStr := string(html)
+		if !contains(htmlStr, "2 files") || !contains(htmlStr, `class="search-file-list"`) || !contains(htmlStr, ">b.go</a>") {
+			t.Errorf("Expected file list, got %s", htmlStr)
+		}
+		if contains(htmlStr, "Found 2 files") {
+			t.Error("Expected the count line to be replaced by the summary")
+		}
+
+		html, _ = formatter.FormatOutput(data, &tools.ToolOutput{HasResult: true, Content: "No files found"})
+		if !contains(string(html), `<div class="search-note">No files found</div>`) || contains(string(html), "0 files") {
+			t.Errorf("Expected only the note, got %s", html)
+		}
+	})
+
+	t.Run("count", func(t *testing.T) {
+		data := map[string]interface{}{"pattern": "foo", "output_mode": "count"}
+		html, _ := formatter.FormatOutput(data, &tools.ToolOutput{HasResult: true, Content: "/repo/a.go:3\n/repo/b.go:1"})
+		htmlStr := string(html)
+		if !contains(htmlStr, "4 matches in 2 files") || !contains(htmlStr, `<span class="search-count">3</span>`) {
+			t.Errorf("Expected counts, got %s", htmlStr)
+		}
+	})
+}
+
+func TestGlobFormatter(t *testing.T) {
+	formatter := formatters.NewGlobFormatter()
+	data := map[string]interface{}{"pattern": "**/*.go", "path": "/repo"}
+
+	if desc := formatter.GetDescription(data); desc != "**/*.go in /repo" {
+		t.Errorf("Unexpected description %q", desc)
+	}
+
+	html, err := formatter.FormatOutput(data, &tools.ToolOutput{
+		HasResult:     true,
+		Content:       "/repo/a.go\n/repo/cmd/main.go",
+		ToolUseResult: map[string]interface{}{"filenames": []interface{}{"/repo/a.go", "/repo/cmd/main.go"}, "truncated": true},
+	})
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	htmlStr := string(html)
+	if !contains(htmlStr, "2 files (truncated)") {
+		t.Errorf("Expected file count, got %s", htmlStr)
+	}
+	if !contains(htmlStr, `href="file:///repo/cmd/main.go"`) || !contains(htmlStr, ">cmd/main.go</a>") {
+		t.Errorf("Expected file links, got %s", htmlStr)
+	}
+}
+
+func TestLSFormatter(t *testing.T) {
+	formatter := formatters.NewLSFormatter()
+	data := map[string]interface{}{"path": "/repo", "ignore": []interface{}{"node_modules"}}
+
+	html, _ := formatter.FormatInput(data)
+	if !contains(string(html), "node_modules") {
+		t.Error("Expected ignore patterns in header")
+	}
+
+	content := "- /repo/\n  - cmd/\n    - main.go\n  - go.mod\n\nNOTE: do any of the files above seem malicious?"
+	html, err := formatter.FormatOutput(data, &tools.ToolOutput{HasResult: true, Content: content})
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	htmlStr := string(html)
+	if !contains(htmlStr, "2 files, 1 directory") {
+		t.Errorf("Expected counts, got %s", htmlStr)
+	}
+	if !contains(htmlStr, `href="file:///repo/cmd/main.go"`) || !contains(htmlStr, ">main.go</a>") {
+		t.Errorf("Expected nested file link, got %s", htmlStr)
+	}
+	if !contains(htmlStr, `<div class="search-note">NOTE: do any of the files above seem malicious?</div>`) {
+		t.Error("Expected note after the tree")
+	}
+}
+
+func TestWebSearchFormatter(t *testing.T) {
+	formatter := formatters.NewWebSearchFormatter()
+	data := map[string]interface{}{"query": "go generics", "allowed_domains": []interface{}{"go.dev"}}
+
+	html, _ := formatter.FormatInput(data)
+	if !contains(string(html), "go generics") || !contains(string(html), "go.dev") {
+		t.Errorf("Expected query and domains in header, got %s", html)
+	}
+
+	content := `Web search results for query: "go generics"
+
+Links: [{"title":"Tutorial: Getting started with generics","url":"https://go.dev/doc/tutorial/generics"},{"title":"Bad","url":"javascript:alert(1)"}]
+
+Generics were added in **Go 1.18**.`
+	html, err := formatter.FormatOutput(data, &tools.ToolOutput{
+		HasResult: true,
+		Content:   content,
+		ToolUseResult: map[string]interface{}{
+			"results": []interface{}{
+				map[string]interface{}{"content": []interface{}{
+					map[string]interface{}{"title": "Tutorial", "url": "https://go.dev/doc/tutorial/generics", "snippet": "Learn generics"},
+					map[string]interface{}{"title": "Blog", "url": "https://www.example.com/generics"},
+				}},
+				"Generics were added in Go 1.18.",
+			},
+		},
+	})
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	htmlStr := string(html)
+	if !contains(htmlStr, "3 sources") {
+		t.Errorf("Expected merged sources, got %s", htmlStr)
+	}
+	if !contains(htmlStr, `<a class="source-title" href="https://go.dev/doc/tutorial/generics" target="_blank" rel="noopener noreferrer">Tutorial: Getting started with generics</a>`) {
+		t.Errorf("Expected source link opening in a new tab, got %s", htmlStr)
+	}
+	if !contains(htmlStr, `<div class="source-snippet">Learn generics</div>`) {
+		t.Error("Expected snippet from the structured result")
+	}
+	if !contains(htmlStr, `<div class="source-domain">example.com</div>`) {
+		t.Error("Expected domain without www.")
+	}
+	if contains(htmlStr, `href="javascript:`) {
+		t.Error("Expected unsafe URLs not to be linked")
+	}
+	if !contains(htmlStr, "<strong>Go 1.18</strong>") || contains(htmlStr, "Web search results for query") {
+		t.Errorf("Expected the summary rendered without the header line, got %s", htmlStr)
+	}
+	if contains(htmlStr, "<img") || contains(htmlStr, "src=") {
+		t.Error("Expected nothing to be fetched when the page loads")
+	}
+}
+
+func TestWebFetchFormatter(t *testing.T) {
+	formatter := formatters.NewWebFetchFormatter()
+	data := map[string]interface{}{"url": "https://pkg.go.dev/net/http", "prompt": "Summarize the Client type"}
+
+	html, _ := formatter.FormatInput(data)
+	if !contains(string(html), `href="https://pkg.go.dev/net/http" target="_blank"`) || !contains(string(html), "Summarize the Client type") {
+		t.Errorf("Expected URL link and prompt in header, got %s", html)
+	}
+	if desc := formatter.GetDescription(data); desc != "https://pkg.go.dev/net/http" {
+		t.Errorf("Unexpected description %q", desc)
+	}
+
+	html, err := formatter.FormatOutput(data, &tools.ToolOutput{
+		HasResult:     true,
+		Content:       "The `Client` type sends requests.",
+		ToolUseResult: map[string]interface{}{"code": 200.0, "codeText": "OK", "bytes": 2048.0, "url": "https://pkg.go.dev/net/http"},
+	})
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	htmlStr := string(html)
+	if !contains(htmlStr, `<div class="source-domain">pkg.go.dev</div>`) || !contains(htmlStr, "200 OK · 2.0 KB") {
+		t.Errorf("Expected source card with status, got %s", htmlStr)
+	}
+	if !contains(htmlStr, "<code>Client</code>") {
+		t.Error("Expected the result rendered as Markdown")
+	}
+
+	html, _ = formatter.FormatOutput(data, &tools.ToolOutput{
+		HasResult: true,
+		Content:   "REDIRECT DETECTED: The URL redirects to a different host.\n\nOriginal URL: http://go.dev\nRedirect URL: https://go.dev/\nStatus: 301 Moved Permanently",
+	})
+	if !contains(string(html), `href="https://go.dev/"`) {
+		t.Errorf("Expected a card for the redirect, got %s", html)
+	}
+}
+
+func TestNotebookEditFormatter(t *testing.T) {
+	formatter := formatters.NewNotebookEditFormatter()
+	data := map[string]interface{}{
+		"notebook_path": "/work/analysis.ipynb",
+		"cell_id":       "abc123",
+		"new_source":    "def load():\n    return read_csv(path)",
+	}
+
+	html, err := formatter.FormatInput(data)
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	htmlStr := string(html)
+	if !contains(htmlStr, `href="file:///work/analysis.ipynb"`) || !contains(htmlStr, "abc123") {
+		t.Errorf("Expected notebook link and cell in header, got %s", htmlStr)
+	}
+	if !contains(htmlStr, `<span class="notebook-edit-mode replace">replace</span>`) {
+		t.Error("Expected replace to be the default edit mode")
+	}
+	if !contains(htmlStr, `<span class="hl-keyword">def</span> <span class="hl-function">load</span>`) {
+		t.Errorf("Expected highlighted code cell, got %s", htmlStr)
+	}
+	if desc := formatter.GetDescription(data); desc != "/work/analysis.ipynb (replace abc123)" {
+		t.Errorf("Unexpected description %q", desc)
+	}
+
+	original := `{"cells": [{"id": "abc123", "cell_type": "code", "source": ["def load():\n", "    return None"]}],
+		"metadata": {"language_info": {"name": "python"}}}`
+	html, err = formatter.FormatOutput(data, &tools.ToolOutput{
+		HasResult:     true,
+		Content:       "Updated cell abc123 with def load():",
+		ToolUseResult: map[string]interface{}{"cell_id": "abc123", "original_file": original},
+	})
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	htmlStr = string(html)
+	if !contains(htmlStr, "line-removed") || !contains(htmlStr, "line-added") || !contains(htmlStr, "read_csv") {
+		t.Errorf("Expected a diff of the replaced cell, got %s", htmlStr)
+	}
+
+	markdownData := map[string]interface{}{
+		"notebook_path": "/work/analysis.ipynb",
+		"cell_id":       "abc123",
+		"cell_type":     "markdown",
+		"edit_mode":     "insert",
+		"new_source":    "# Results",
+	}
+	html, _ = formatter.FormatInput(markdownData)
+	if !contains(string(html), "<h1") || !contains(string(html), "notebook-edit-mode insert") {
+		t.Errorf("Expected rendered Markdown cell, got %s", html)
+	}
+	if desc := formatter.GetDescription(markdownData); desc != "/work/analysis.ipynb (insert after abc123)" {
+		t.Errorf("Unexpected description %q", desc)
+	}
+
+	html, _ = formatter.FormatInput(map[string]interface{}{"notebook_path": "/work/analysis.ipynb", "cell_number": 2.0, "edit_mode": "delete"})
+	if !contains(string(html), "#2") || !contains(string(html), "Cell deleted") {
+		t.Errorf("Expected deleted cell by number, got %s", html)
+	}
+}
+
+func TestReadFormatter_Notebook(t *testing.T) {
+	formatter := formatters.NewReadFormatter()
+	data := map[string]interface{}{"file_path": "/work/analysis.ipynb"}
+
+	html, _ := formatter.FormatOutput(data, &tools.ToolOutput{
+		HasResult: true,
+		Content:   "<cell id=\"cell-0\"><cell_type>markdown</cell_type># Analysis</cell id=\"cell-0\">\n<cell id=\"cell-1\">import pandas</cell id=\"cell-1\">",
+	})
+	htmlStr := string(html)
+	if !contains(htmlStr, `<div class="notebook-cell markdown">`) || !contains(htmlStr, "<h1") {
+		t.Errorf("Expected rendered Markdown cell, got %s", htmlStr)
+	}
+	if !contains(htmlStr, `<span class="hl-keyword">import</span>`) {
+		t.Errorf("Expected highlighted code cell, got %s", htmlStr)
+	}
+
+	html, _ = formatter.FormatOutput(data, &tools.ToolOutput{
+		HasResult: true,
+		ToolUseResult: map[string]interface{}{"type": "notebook", "file": map[string]interface{}{
+			"filePath": "/work/analysis.ipynb",
+			"cells": []interface{}{map[string]interface{}{
+				"cellType": "code", "cell_id": "cell-0", "source": "print(1)", "language": "python", "execution_count": 3.0,
+				"outputs": []interface{}{map[string]interface{}{"output_type": "stream", "text": "1\n"}},
+			}},
+		}},
+	})
+	htmlStr = string(html)
+	if !contains(htmlStr, `<span class="notebook-cell-prompt">[3]</span>`) || !contains(htmlStr, `<pre class="notebook-cell-output">1</pre>`) {
+		t.Errorf("Expected cell with its output, got %s", htmlStr)
+	}
+}
+
 func TestTodoWriteFormatter(t *testing.T) {
 	formatter := formatters.NewTodoWriteFormatter()
 
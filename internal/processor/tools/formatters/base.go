@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"html/template"
 
+	"github.com/brads3290/cclogviewer/internal/highlight"
+	"github.com/brads3290/cclogviewer/internal/processor/tools"
 	"github.com/brads3290/cclogviewer/internal/utils"
 )
 
@@ -17,10 +19,9 @@ func (b *BaseFormatter) Name() string {
 	return b.toolName
 }
 
-// FormatOutput provides a default implementation for output formatting
-func (b *BaseFormatter) FormatOutput(output interface{}) (template.HTML, error) {
-	// Default implementation - most tools don't need special output formatting
-	return template.HTML(""), nil
+// FormatOutput provides a default implementation showing the result as text
+func (b *BaseFormatter) FormatOutput(data map[string]interface{}, output *tools.ToolOutput) (template.HTML, error) {
+	return tools.FormatGenericOutput(output), nil
 }
 
 // GetCompactView provides a default implementation returning empty
@@ -28,6 +29,15 @@ func (b *BaseFormatter) GetCompactView(data map[string]interface{}) template.HTM
 	return template.HTML("")
 }
 
+// formatErrorOutput shows the result of a call only if the call failed, for tools whose
+// input already shows what a successful call did
+func (b *BaseFormatter) formatErrorOutput(output *tools.ToolOutput) template.HTML {
+	if output == nil || !output.IsError {
+		return template.HTML("")
+	}
+	return tools.FormatGenericOutput(output)
+}
+
 // Helper methods that delegate to utils package
 func (b *BaseFormatter) extractString(data map[string]interface{}, key string) string {
 	return utils.ExtractString(data, key)
@@ -59,9 +69,10 @@ func (b *BaseFormatter) formatPath(path string) string {
 	return fmt.Sprintf(`<span class="file-path">%s</span>`, utils.EscapeHTML(path))
 }
 
-// formatCode formats code content with proper escaping
-func (b *BaseFormatter) formatCode(code string) string {
-	return fmt.Sprintf(`<pre class="code-content">%s</pre>`, utils.EscapeHTML(code))
+// formatCode formats the content of a file with proper escaping, highlighting its syntax
+// by the file type
+func (b *BaseFormatter) formatCode(path, code string) string {
+	return fmt.Sprintf(`<pre class="code-content">%s</pre>`, highlight.Code(path, code))
 }
 
 // formatInlineCode formats inline code
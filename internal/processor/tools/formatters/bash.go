@@ -3,6 +3,7 @@ package formatters
 import (
 	"fmt"
 	"html/template"
+	"strings"
 
 	"github.com/brads3290/cclogviewer/internal/constants"
 	"github.com/brads3290/cclogviewer/internal/processor/tools"
@@ -14,8 +15,11 @@ type BashFormatter struct {
 	BaseFormatter
 }
 
-// Ensure BashFormatter implements tools.BashFormatter interface
-var _ tools.BashFormatter = (*BashFormatter)(nil)
+// Ensure BashFormatter implements tools.BashFormatter and tools.InlineFormatter interfaces
+var (
+	_ tools.BashFormatter   = (*BashFormatter)(nil)
+	_ tools.InlineFormatter = (*BashFormatter)(nil)
+)
 
 // NewBashFormatter creates a new Bash formatter
 func NewBashFormatter() *BashFormatter {
@@ -26,62 +30,125 @@ func NewBashFormatter() *BashFormatter {
 
 // FormatInput formats the input for the Bash tool
 func (f *BashFormatter) FormatInput(data map[string]interface{}) (template.HTML, error) {
-	// For Bash, we return empty HTML since we'll handle formatting in FormatInputWithCWD
+	// For Bash, we return empty HTML since the command is shown by FormatOutput
 	return template.HTML(""), nil
 }
 
 // FormatInputWithCWD formats the input for the Bash tool with current working directory
 func (f *BashFormatter) FormatInputWithCWD(data map[string]interface{}, cwd string) (template.HTML, error) {
-	command := f.extractString(data, "command")
-	description := f.extractString(data, "description")
+	return template.HTML(f.formatTerminal(data, cwd, "")), nil
+}
+
+// FormatOutput formats the whole Bash call as a terminal: the command, then its output
+func (f *BashFormatter) FormatOutput(data map[string]interface{}, output *tools.ToolOutput) (template.HTML, error) {
+	if output == nil {
+		output = &tools.ToolOutput{}
+	}
+	return template.HTML(f.formatTerminal(data, output.CWD, output.Content)), nil
+}
+
+// IsInline reports that the terminal shows the whole Bash call
+func (f *BashFormatter) IsInline() bool {
+	return true
+}
+
+// ValidateInput validates the input for the Bash tool
+func (f *BashFormatter) ValidateInput(data map[string]interface{}) error {
+	return utils.ValidateRequiredField(data, "command")
+}
+
+// GetDescription returns a custom description for the Bash tool
+func (f *BashFormatter) GetDescription(data map[string]interface{}) string {
+	// Clear description for Bash since we show it in the custom display
+	return ""
+}
+
+// formatTerminal builds the terminal display of a Bash call
+func (f *BashFormatter) formatTerminal(data map[string]interface{}, cwd, output string) string {
+	command := strings.TrimSpace(f.extractString(data, "command"))
+	description := strings.TrimSpace(f.extractString(data, "description"))
 	timeout := f.extractFloat(data, "timeout")
 
-	// Build the bash display HTML
-	var html string
-	html += `<div class="bash-display">`
+	var sb strings.Builder
+	sb.WriteString(`<div class="bash-display">`)
 
 	// Header with terminal icon and description
-	html += `<div class="bash-header">`
-	html += `<span class="terminal-icon">💻</span>`
-	html += fmt.Sprintf(`<span class="command-label">%s</span>`, constants.ToolNameBash)
+	sb.WriteString(`<div class="bash-header">`)
+	sb.WriteString(`<span class="terminal-icon">💻</span>`)
+	sb.WriteString(fmt.Sprintf(`<span class="command-label">%s</span>`, constants.ToolNameBash))
 	if description != "" {
-		html += fmt.Sprintf(`<span class="description">%s</span>`, f.escapeHTML(description))
+		sb.WriteString(fmt.Sprintf(`<span class="description">%s</span>`, f.escapeHTML(description)))
 	}
-	html += `</div>`
+	sb.WriteString(`</div>`)
 
 	// Terminal display
-	html += `<div class="bash-terminal">`
+	sb.WriteString(`<div class="bash-terminal">`)
 
 	// Show timeout if specified
 	if timeout > 0 {
-		html += fmt.Sprintf(`<span class="bash-timeout">timeout: %dms</span>`, int(timeout))
+		sb.WriteString(fmt.Sprintf(`<span class="bash-timeout">timeout: %dms</span>`, int(timeout)))
 	}
 
 	// Current working directory
 	if cwd != "" {
-		html += fmt.Sprintf(`<div class="bash-cwd">%s</div>`, f.escapeHTML(cwd))
+		sb.WriteString(fmt.Sprintf(`<div class="bash-cwd">%s</div>`, f.escapeHTML(cwd)))
 	}
 
 	// Command line with prompt
-	html += `<div class="bash-command-line">`
-	html += `<span class="bash-prompt">$</span>`
-	html += fmt.Sprintf(`<span class="bash-command">%s</span>`, f.escapeHTML(command))
-	html += `</div>`
+	sb.WriteString(`<div class="bash-command-line">`)
+	sb.WriteString(`<span class="bash-prompt">$</span>`)
+	sb.WriteString(fmt.Sprintf(`<span class="bash-command">%s</span>`, f.escapeHTML(command)))
+	sb.WriteString(`</div>`)
 
-	// Note: The result will be added by the template when rendering
-	html += `</div>`
-	html += `</div>`
+	if output != "" {
+		f.writeOutput(&sb, output)
+	}
 
-	return template.HTML(html), nil
-}
+	sb.WriteString(`</div>`)
+	sb.WriteString(`</div>`)
 
-// ValidateInput validates the input for the Bash tool
-func (f *BashFormatter) ValidateInput(data map[string]interface{}) error {
-	return utils.ValidateRequiredField(data, "command")
+	return sb.String()
 }
 
-// GetDescription returns a custom description for the Bash tool
-func (f *BashFormatter) GetDescription(data map[string]interface{}) string {
-	// Clear description for Bash since we show it in the custom display
-	return ""
+// writeOutput writes the command output, collapsing the lines past the threshold behind a
+// More link
+func (f *BashFormatter) writeOutput(sb *strings.Builder, output string) {
+	lines := strings.Split(output, "\n")
+	if len(lines) <= constants.BashOutputCollapseThreshold {
+		sb.WriteString(`<div class="bash-output">`)
+		sb.WriteString(strings.ReplaceAll(tools.ConvertANSIToHTML(output), "\n", "<br>"))
+		sb.WriteString(`</div>`)
+		return
+	}
+
+	sb.WriteString(`<div class="bash-output" style="position: relative;">`)
+
+	// The first lines are always visible
+	for i, line := range lines[:constants.BashOutputCollapseThreshold] {
+		if i > 0 {
+			sb.WriteString("<br>")
+		}
+		sb.WriteString(tools.ConvertANSIToHTML(line))
+	}
+
+	// Hidden lines
+	sb.WriteString(`<div class="bash-more-content" style="display: none;">`)
+	for _, line := range lines[constants.BashOutputCollapseThreshold:] {
+		sb.WriteString("<br>")
+		sb.WriteString(tools.ConvertANSIToHTML(line))
+	}
+	sb.WriteString(`</div>`)
+
+	// More/Less toggle
+	sb.WriteString(`<div style="margin-top: 5px;">`)
+	sb.WriteString(`<a href="#" class="bash-more-link" style="color: #0066cc; text-decoration: none;" onclick="`)
+	sb.WriteString(`event.preventDefault(); `)
+	sb.WriteString(`var content = this.parentElement.previousElementSibling; `)
+	sb.WriteString(`var isHidden = content.style.display === 'none'; `)
+	sb.WriteString(`content.style.display = isHidden ? 'block' : 'none'; `)
+	sb.WriteString(`this.textContent = isHidden ? 'Less' : 'More'; `)
+	sb.WriteString(`return false;">More</a>`)
+	sb.WriteString(`</div>`)
+
+	sb.WriteString(`</div>`)
 }
@@ -4,6 +4,7 @@ import (
 	"html/template"
 
 	"github.com/brads3290/cclogviewer/internal/constants"
+	"github.com/brads3290/cclogviewer/internal/processor/tools"
 	"github.com/brads3290/cclogviewer/internal/processor/tools/diff"
 	"github.com/brads3290/cclogviewer/internal/utils"
 )
@@ -22,14 +23,27 @@ func NewEditFormatter() *EditFormatter {
 
 // FormatInput formats the input for the Edit tool
 func (f *EditFormatter) FormatInput(data map[string]interface{}) (template.HTML, error) {
+	return f.FormatInputAtLines(data, nil)
+}
+
+// FormatInputAtLines formats the input for the Edit tool, numbering the diff by the file
+// line the edit starts at
+func (f *EditFormatter) FormatInputAtLines(data map[string]interface{}, lines []int) (template.HTML, error) {
 	oldString := f.extractString(data, "old_string")
 	newString := f.extractString(data, "new_string")
 
-	// Compute the diff
-	diffLines := diff.ComputeLineDiff(oldString, newString)
+	// Compute the diff, highlighting the changed words of modified lines
+	diffLines := diff.HighlightChanges(diff.ComputeLineDiff(oldString, newString))
+	diffLines = diff.HighlightSyntax(diffLines, f.extractString(data, "file_path"))
 
 	// Format as HTML
-	return diff.FormatDiffHTML(diffLines), nil
+	return formatPlacedDiff(diffLines, lines, 0), nil
+}
+
+// FormatOutput shows the result of the Edit tool only if it failed, since the diff of the
+// input already shows the change
+func (f *EditFormatter) FormatOutput(data map[string]interface{}, output *tools.ToolOutput) (template.HTML, error) {
+	return f.formatErrorOutput(output), nil
 }
 
 // ValidateInput validates the input for the Edit tool
@@ -49,3 +63,18 @@ func (f *EditFormatter) GetDescription(data map[string]interface{}) string {
 
 	return desc
 }
+
+// formatPlacedDiff formats the diff of the i-th edit of a tool call. If the file lines of the
+// call's edits were looked up but this one wasn't found, the diff is marked as unplaced.
+func formatPlacedDiff(diffLines []diff.DiffLine, lines []int, i int) template.HTML {
+	if lines == nil {
+		return diff.FormatDiffHTML(diffLines)
+	}
+
+	if i < len(lines) && lines[i] > 0 {
+		return diff.FormatDiffHTML(diff.PlaceAt(diffLines, lines[i]))
+	}
+
+	return `<div class="diff-location-unknown" title="The file content before this edit is not in the log">Position in file unknown</div>` +
+		diff.FormatDiffHTML(diffLines)
+}
@@ -0,0 +1,61 @@
+package formatters
+
+import (
+	"html/template"
+
+	"github.com/brads3290/cclogviewer/internal/constants"
+	"github.com/brads3290/cclogviewer/internal/processor/tools"
+	"github.com/brads3290/cclogviewer/internal/utils"
+)
+
+// GlobFormatter formats Glob tool inputs and outputs.
+type GlobFormatter struct {
+	BaseFormatter
+}
+
+// NewGlobFormatter creates a new Glob formatter
+func NewGlobFormatter() *GlobFormatter {
+	return &GlobFormatter{
+		BaseFormatter: BaseFormatter{toolName: constants.ToolNameGlob},
+	}
+}
+
+// FormatInput formats the input for the Glob tool
+func (f *GlobFormatter) FormatInput(data map[string]interface{}) (template.HTML, error) {
+	return template.HTML(formatSearchHeader("📁", f.extractString(data, "pattern"), []searchField{
+		{label: "in", value: f.extractString(data, "path")},
+	})), nil
+}
+
+// FormatOutput shows the files the Glob tool found as a list of links
+func (f *GlobFormatter) FormatOutput(data map[string]interface{}, output *tools.ToolOutput) (template.HTML, error) {
+	if output == nil || !output.HasResult || output.IsError {
+		return tools.FormatGenericOutput(output), nil
+	}
+
+	paths, notes := parseFileList(output.Content)
+	truncated := false
+	if payload, ok := output.ToolUseResult.(map[string]interface{}); ok {
+		if filenames := stringSlice(utils.ExtractSlice(payload, "filenames")); filenames != nil {
+			paths = filenames
+		}
+		truncated = utils.ExtractBool(payload, "truncated")
+	}
+
+	base := searchBase(f.extractString(data, "path"), output.CWD)
+	return template.HTML(formatFileResults(paths, notes, base, truncated)), nil
+}
+
+// ValidateInput validates the input for the Glob tool
+func (f *GlobFormatter) ValidateInput(data map[string]interface{}) error {
+	return utils.ValidateRequiredField(data, "pattern")
+}
+
+// GetDescription returns the pattern and the directory searched
+func (f *GlobFormatter) GetDescription(data map[string]interface{}) string {
+	desc := f.extractString(data, "pattern")
+	if path := f.extractString(data, "path"); path != "" {
+		desc += " in " + path
+	}
+	return desc
+}
@@ -0,0 +1,410 @@
+package formatters
+
+import (
+	"fmt"
+	"html/template"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/brads3290/cclogviewer/internal/constants"
+	"github.com/brads3290/cclogviewer/internal/processor/tools"
+	"github.com/brads3290/cclogviewer/internal/utils"
+)
+
+var (
+	// grepNumberedLinePattern matches a line of a single file search: the line number, then
+	// ":" for a match or "-" for a context line
+	grepNumberedLinePattern = regexp.MustCompile(`^(\d+)([:-])(.*)$`)
+	// grepFileMatchLinePattern matches a match with the file and line number. It is tried
+	// before the context form, since paths like 2024-01-15-notes.md hold "-<digits>-".
+	grepFileMatchLinePattern = regexp.MustCompile(`^(.+?):(\d+):(.*)$`)
+	// grepFileContextLinePattern matches a context line with the file and line number
+	grepFileContextLinePattern = regexp.MustCompile(`^(.+?)-(\d+)-(.*)$`)
+	// grepContextRestPattern matches the rest of a context line after its file
+	grepContextRestPattern = regexp.MustCompile(`^(\d+)-(.*)$`)
+	// grepFileMatchPattern matches a match without a line number
+	grepFileMatchPattern = regexp.MustCompile(`^(.+?):(.*)$`)
+	// grepCountPattern matches a file and its number of matches in count mode
+	grepCountPattern = regexp.MustCompile(`^(.+):(\d+)$`)
+)
+
+// grepLine is a line of a Grep result in content mode
+type grepLine struct {
+	path      string
+	lineNum   int // 0 if the result has no line numbers
+	text      string
+	context   bool // Whether the line is context around a match rather than a match
+	separator bool // Whether the line marks a gap between groups of context lines
+}
+
+// grepFile is the lines of a Grep result found in one file
+type grepFile struct {
+	path  string
+	lines []grepLine
+}
+
+// GrepFormatter formats Grep tool inputs and outputs.
+type GrepFormatter struct {
+	BaseFormatter
+}
+
+// NewGrepFormatter creates a new Grep formatter
+func NewGrepFormatter() *GrepFormatter {
+	return &GrepFormatter{
+		BaseFormatter: BaseFormatter{toolName: constants.ToolNameGrep},
+	}
+}
+
+// FormatInput formats the input for the Grep tool
+func (f *GrepFormatter) FormatInput(data map[string]interface{}) (template.HTML, error) {
+	var flags []string
+	if f.extractBool(data, "-i") {
+		flags = append(flags, "case insensitive")
+	}
+	if f.extractBool(data, "multiline") {
+		flags = append(flags, "multiline")
+	}
+	for _, flag := range []string{"-A", "-B", "-C"} {
+		if n := f.extractInt(data, flag); n > 0 {
+			flags = append(flags, fmt.Sprintf("%s %d", flag, n))
+		}
+	}
+	if n := f.extractInt(data, "head_limit"); n > 0 {
+		flags = append(flags, fmt.Sprintf("first %d", n))
+	}
+
+	return template.HTML(formatSearchHeader("🔍", f.extractString(data, "pattern"), []searchField{
+		{label: "in", value: f.extractString(data, "path")},
+		{label: "glob", value: f.extractString(data, "glob")},
+		{label: "type", value: f.extractString(data, "type")},
+		{label: "mode", value: f.outputMode(data)},
+		{label: "options", value: strings.Join(flags, ", ")},
+	})), nil
+}
+
+// FormatOutput shows the files or lines the Grep tool found, depending on its output mode
+func (f *GrepFormatter) FormatOutput(data map[string]interface{}, output *tools.ToolOutput) (template.HTML, error) {
+	if output == nil || !output.HasResult || output.IsError {
+		return tools.FormatGenericOutput(output), nil
+	}
+
+	path := f.extractString(data, "path")
+	base := searchBase(path, output.CWD)
+
+	switch f.outputMode(data) {
+	case constants.GrepOutputModeContent:
+		return template.HTML(f.formatContent(data, output.Content, base)), nil
+	case constants.GrepOutputModeCount:
+		return template.HTML(f.formatCounts(output.Content, base)), nil
+	default:
+		paths, notes := parseFileList(output.Content)
+		if payload, ok := output.ToolUseResult.(map[string]interface{}); ok {
+			if filenames := stringSlice(utils.ExtractSlice(payload, "filenames")); filenames != nil {
+				paths = filenames
+			}
+		}
+		return template.HTML(formatFileResults(paths, notes, base, false)), nil
+	}
+}
+
+// ValidateInput validates the input for the Grep tool
+func (f *GrepFormatter) ValidateInput(data map[string]interface{}) error {
+	return utils.ValidateRequiredField(data, "pattern")
+}
+
+// GetDescription returns the pattern and where it was searched for
+func (f *GrepFormatter) GetDescription(data map[string]interface{}) string {
+	desc := f.extractString(data, "pattern")
+	if path := f.extractString(data, "path"); path != "" {
+		desc += " in " + path
+	}
+	if glob := f.extractString(data, "glob"); glob != "" {
+		desc += " (" + glob + ")"
+	}
+	return desc
+}
+
+// outputMode returns the output mode of a Grep call
+func (f *GrepFormatter) outputMode(data map[string]interface{}) string {
+	if mode := f.extractString(data, "output_mode"); mode != "" {
+		return mode
+	}
+	return constants.GrepOutputModeFiles
+}
+
+// matchPattern compiles the pattern of a Grep call to highlight the matches, or returns nil
+// if Go doesn't support the pattern
+func (f *GrepFormatter) matchPattern(data map[string]interface{}) *regexp.Regexp {
+	pattern := f.extractString(data, "pattern")
+	if pattern == "" {
+		return nil
+	}
+	if f.extractBool(data, "-i") {
+		pattern = "(?i)" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil
+	}
+	return re
+}
+
+// formatContent formats the matching lines of a Grep result, grouped by file
+func (f *GrepFormatter) formatContent(data map[string]interface{}, content, base string) string {
+	files, notes := parseGrepContent(content, base)
+	re := f.matchPattern(data)
+
+	matches := 0
+	for _, file := range files {
+		for _, line := range file.lines {
+			if !line.context && !line.separator {
+				matches++
+			}
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString(`<div class="search-results">`)
+	if len(files) > 0 || len(notes) == 0 {
+		sb.WriteString(formatSearchSummary(fmt.Sprintf("%s in %s",
+			countNoun(matches, "match", "matches"), countNoun(len(files), "file", "files"))))
+	}
+
+	for _, file := range files {
+		sb.WriteString(`<div class="grep-file">`)
+		sb.WriteString(`<div class="grep-file-header">`)
+		if file.path != "" {
+			sb.WriteString(formatFileLink(file.path, base))
+		}
+		sb.WriteString(`</div>`)
+
+		for i, line := range file.lines {
+			if line.separator {
+				// Groups of other files may have ended here
+				if i > 0 && i < len(file.lines)-1 && !file.lines[i+1].separator {
+					sb.WriteString(`<div class="grep-separator">⋯</div>`)
+				}
+				continue
+			}
+
+			class := "grep-line"
+			if line.context {
+				class += " context"
+			}
+			sb.WriteString(fmt.Sprintf(`<div class="%s">`, class))
+			if line.lineNum > 0 {
+				sb.WriteString(fmt.Sprintf(`<span class="line-number">%d</span>`, line.lineNum))
+			}
+			sb.WriteString(`<span class="line-content">`)
+			if line.context {
+				sb.WriteString(utils.EscapeHTML(line.text))
+			} else {
+				sb.WriteString(highlightMatches(line.text, re))
+			}
+			sb.WriteString(`</span>`)
+			sb.WriteString(`</div>`)
+		}
+		sb.WriteString(`</div>`)
+	}
+
+	sb.WriteString(formatSearchNotes(notes))
+	sb.WriteString(`</div>`)
+	return sb.String()
+}
+
+// formatCounts formats a Grep result in count mode as a list of files with their number of matches
+func (f *GrepFormatter) formatCounts(content, base string) string {
+	type fileCount struct {
+		path  string
+		count int
+	}
+	var counts []fileCount
+	var notes []string
+	total := 0
+
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || foundCountPattern.MatchString(line) {
+			continue
+		}
+		if match := grepCountPattern.FindStringSubmatch(line); match != nil {
+			count, _ := strconv.Atoi(match[2])
+			counts = append(counts, fileCount{path: match[1], count: count})
+			total += count
+			continue
+		}
+		notes = append(notes, line)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(`<div class="search-results">`)
+	if len(counts) > 0 || len(notes) == 0 {
+		sb.WriteString(formatSearchSummary(fmt.Sprintf("%s in %s",
+			countNoun(total, "match", "matches"), countNoun(len(counts), "file", "files"))))
+	}
+	if len(counts) > 0 {
+		sb.WriteString(`<ul class="search-file-list">`)
+		for _, count := range counts {
+			sb.WriteString(`<li>`)
+			sb.WriteString(formatFileLink(count.path, base))
+			sb.WriteString(fmt.Sprintf(` <span class="search-count">%d</span>`, count.count))
+			sb.WriteString(`</li>`)
+		}
+		sb.WriteString(`</ul>`)
+	}
+	sb.WriteString(formatSearchNotes(notes))
+	sb.WriteString(`</div>`)
+	return sb.String()
+}
+
+// parseGrepContent groups the lines of a Grep result in content mode by file. Lines of a
+// search of a single file have no file name and are given the searched path. Lines that
+// aren't results are returned as notes.
+func parseGrepContent(content, searchPath string) ([]*grepFile, []string) {
+	var files []*grepFile
+	var notes []string
+	byPath := make(map[string]*grepFile)
+
+	add := func(line grepLine) {
+		file := byPath[line.path]
+		if file == nil {
+			file = &grepFile{path: line.path}
+			byPath[line.path] = file
+			files = append(files, file)
+		}
+		file.lines = append(file.lines, line)
+	}
+
+	lines := strings.Split(content, "\n")
+	matchPaths := grepMatchPaths(lines)
+	// Only lines of a single file search start with a bare line number, which file names
+	// starting with digits, like 2024-01-15-notes.md, could be mistaken for
+	singleFile := len(matchPaths) == 0 && !grepHasFileNames(lines)
+	var last *grepFile
+	for _, text := range lines {
+		if text == "" || foundCountPattern.MatchString(text) {
+			continue
+		}
+		if text == "--" {
+			// Separates groups of context lines
+			if last != nil {
+				last.lines = append(last.lines, grepLine{separator: true})
+			}
+			continue
+		}
+
+		line, ok := parseGrepLine(text, searchPath, matchPaths, singleFile)
+		if !ok {
+			notes = append(notes, strings.TrimSpace(text))
+			continue
+		}
+		add(line)
+		last = byPath[line.path]
+	}
+
+	return files, notes
+}
+
+// grepMatchPaths returns the files of the matches of a Grep result, longest first, so that
+// context lines can be read as lines of these files
+func grepMatchPaths(lines []string) []string {
+	seen := make(map[string]bool)
+	var paths []string
+	for _, text := range lines {
+		if match := grepFileMatchLinePattern.FindStringSubmatch(text); match != nil && looksLikePath(match[1]) && !seen[match[1]] {
+			seen[match[1]] = true
+			paths = append(paths, match[1])
+		}
+	}
+	sort.SliceStable(paths, func(i, j int) bool {
+		return len(paths[i]) > len(paths[j])
+	})
+	return paths
+}
+
+// grepHasFileNames reports whether the lines of a Grep result without line numbers start
+// with file names
+func grepHasFileNames(lines []string) bool {
+	for _, text := range lines {
+		if grepNumberedLinePattern.MatchString(text) {
+			continue
+		}
+		if match := grepFileMatchPattern.FindStringSubmatch(text); match != nil && looksLikePath(match[1]) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseGrepLine parses a line of a Grep result in content mode. Lines of a single file
+// search have no file name. A context line starting with the file of a match is read as a
+// line of that file, so that paths holding "-<digits>-" aren't split there.
+func parseGrepLine(text, searchPath string, matchPaths []string, singleFile bool) (grepLine, bool) {
+	if match := grepNumberedLinePattern.FindStringSubmatch(text); match != nil && singleFile {
+		lineNum, _ := strconv.Atoi(match[1])
+		return grepLine{path: searchPath, lineNum: lineNum, text: match[3], context: match[2] == "-"}, true
+	}
+	if match := grepFileMatchLinePattern.FindStringSubmatch(text); match != nil && looksLikePath(match[1]) {
+		lineNum, _ := strconv.Atoi(match[2])
+		return grepLine{path: match[1], lineNum: lineNum, text: match[3]}, true
+	}
+	for _, path := range matchPaths {
+		rest, ok := strings.CutPrefix(text, path+"-")
+		if !ok {
+			continue
+		}
+		if match := grepContextRestPattern.FindStringSubmatch(rest); match != nil {
+			lineNum, _ := strconv.Atoi(match[1])
+			return grepLine{path: path, lineNum: lineNum, text: match[2], context: true}, true
+		}
+	}
+	if match := grepFileContextLinePattern.FindStringSubmatch(text); match != nil && looksLikePath(match[1]) {
+		lineNum, _ := strconv.Atoi(match[2])
+		return grepLine{path: match[1], lineNum: lineNum, text: match[3], context: true}, true
+	}
+	if match := grepFileMatchPattern.FindStringSubmatch(text); match != nil && looksLikePath(match[1]) {
+		return grepLine{path: match[1], text: match[2]}, true
+	}
+	return grepLine{}, false
+}
+
+// looksLikePath reports whether the start of a Grep result line is a file path. Colons
+// other than that of a Windows drive mean the start is a line number or text instead.
+func looksLikePath(s string) bool {
+	if s == "" || strings.TrimSpace(s) != s {
+		return false
+	}
+	rest := s
+	if len(s) > 1 && s[1] == ':' {
+		rest = s[2:]
+	}
+	if strings.Contains(rest, ":") {
+		return false
+	}
+	return strings.Contains(s, "/") || strings.Contains(s, `\`) || filepath.Ext(s) != ""
+}
+
+// highlightMatches escapes a matching line, marking the text the pattern matches
+func highlightMatches(text string, re *regexp.Regexp) string {
+	if re == nil {
+		return utils.EscapeHTML(text)
+	}
+
+	var sb strings.Builder
+	last := 0
+	for _, loc := range re.FindAllStringIndex(text, -1) {
+		if loc[0] == loc[1] {
+			continue
+		}
+		sb.WriteString(utils.EscapeHTML(text[last:loc[0]]))
+		sb.WriteString(`<mark class="search-match">`)
+		sb.WriteString(utils.EscapeHTML(text[loc[0]:loc[1]]))
+		sb.WriteString(`</mark>`)
+		last = loc[1]
+	}
+	sb.WriteString(utils.EscapeHTML(text[last:]))
+	return sb.String()
+}
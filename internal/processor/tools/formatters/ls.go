@@ -0,0 +1,167 @@
+package formatters
+
+import (
+	"fmt"
+	"html/template"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/brads3290/cclogviewer/internal/constants"
+	"github.com/brads3290/cclogviewer/internal/processor/tools"
+	"github.com/brads3290/cclogviewer/internal/utils"
+)
+
+// lsEntryPattern matches an entry of an LS result: two spaces of indentation per level,
+// then "- " and the name, with a trailing slash for directories
+var lsEntryPattern = regexp.MustCompile(`^((?:  )*)- (.+)$`)
+
+// lsNode is a file or directory of an LS result
+type lsNode struct {
+	name     string
+	path     string
+	dir      bool
+	children []*lsNode
+}
+
+// LSFormatter formats LS tool inputs and outputs.
+type LSFormatter struct {
+	BaseFormatter
+}
+
+// NewLSFormatter creates a new LS formatter
+func NewLSFormatter() *LSFormatter {
+	return &LSFormatter{
+		BaseFormatter: BaseFormatter{toolName: constants.ToolNameLS},
+	}
+}
+
+// FormatInput formats the input for the LS tool
+func (f *LSFormatter) FormatInput(data map[string]interface{}) (template.HTML, error) {
+	ignore := stringSlice(f.extractSlice(data, "ignore"))
+	return template.HTML(formatSearchHeader("📂", f.extractString(data, "path"), []searchField{
+		{label: "ignore", value: strings.Join(ignore, ", ")},
+	})), nil
+}
+
+// FormatOutput shows the directory tree listed by the LS tool
+func (f *LSFormatter) FormatOutput(data map[string]interface{}, output *tools.ToolOutput) (template.HTML, error) {
+	if output == nil || !output.HasResult || output.IsError {
+		return tools.FormatGenericOutput(output), nil
+	}
+
+	roots, notes := parseLSTree(output.Content, searchBase(f.extractString(data, "path"), output.CWD))
+	if len(roots) == 0 {
+		return tools.FormatGenericOutput(output), nil
+	}
+
+	files, dirs := 0, 0
+	var count func(nodes []*lsNode)
+	count = func(nodes []*lsNode) {
+		for _, node := range nodes {
+			if node.dir {
+				dirs++
+			} else {
+				files++
+			}
+			count(node.children)
+		}
+	}
+	for _, root := range roots {
+		count(root.children)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(`<div class="search-results">`)
+	sb.WriteString(formatSearchSummary(fmt.Sprintf("%s, %s",
+		countNoun(files, "file", "files"), countNoun(dirs, "directory", "directories"))))
+	sb.WriteString(`<ul class="ls-tree">`)
+	for _, root := range roots {
+		writeLSNode(&sb, root)
+	}
+	sb.WriteString(`</ul>`)
+	sb.WriteString(formatSearchNotes(notes))
+	sb.WriteString(`</div>`)
+
+	return template.HTML(sb.String()), nil
+}
+
+// ValidateInput validates the input for the LS tool
+func (f *LSFormatter) ValidateInput(data map[string]interface{}) error {
+	return utils.ValidateRequiredField(data, "path")
+}
+
+// GetDescription returns the directory listed
+func (f *LSFormatter) GetDescription(data map[string]interface{}) string {
+	return f.extractString(data, "path")
+}
+
+// parseLSTree parses the entries of an LS result into trees. Top-level entries are full
+// paths; other entries are named relative to their parent. Lines that aren't entries, like
+// the note after the tree, are returned as notes.
+func parseLSTree(content, base string) ([]*lsNode, []string) {
+	var roots []*lsNode
+	var notes []string
+	var stack []*lsNode // Open directories, by depth
+
+	for _, line := range strings.Split(content, "\n") {
+		match := lsEntryPattern.FindStringSubmatch(line)
+		if match == nil {
+			if note := strings.TrimSpace(line); note != "" {
+				notes = append(notes, note)
+			}
+			continue
+		}
+
+		depth := len(match[1]) / 2
+		name := match[2]
+		node := &lsNode{name: name, dir: strings.HasSuffix(name, "/")}
+		cleanName := strings.TrimSuffix(name, "/")
+
+		if depth > len(stack) {
+			// Indented deeper than any open directory
+			notes = append(notes, strings.TrimSpace(line))
+			continue
+		}
+		stack = stack[:depth]
+
+		if depth == 0 {
+			node.path = cleanName
+			if !filepath.IsAbs(node.path) && base != "" {
+				node.path = filepath.Join(base, node.path)
+			}
+			roots = append(roots, node)
+		} else {
+			parent := stack[depth-1]
+			node.path = filepath.Join(parent.path, cleanName)
+			parent.children = append(parent.children, node)
+		}
+
+		if node.dir {
+			stack = append(stack, node)
+		}
+	}
+
+	return roots, notes
+}
+
+// writeLSNode writes a node of an LS tree with its children
+func writeLSNode(sb *strings.Builder, node *lsNode) {
+	if !node.dir {
+		sb.WriteString(`<li class="ls-file">`)
+		sb.WriteString(formatFileLink(node.path, filepath.Dir(node.path)))
+		sb.WriteString(`</li>`)
+		return
+	}
+
+	sb.WriteString(`<li class="ls-dir"><details open>`)
+	sb.WriteString(fmt.Sprintf(`<summary title="%s">%s</summary>`, utils.EscapeHTML(node.path), utils.EscapeHTML(node.name)))
+	if len(node.children) > 0 {
+		sb.WriteString(`<ul>`)
+		for _, child := range node.children {
+			writeLSNode(sb, child)
+		}
+		sb.WriteString(`</ul>`)
+	}
+	sb.WriteString(`</details></li>`)
+}
@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/brads3290/cclogviewer/internal/constants"
+	"github.com/brads3290/cclogviewer/internal/processor/tools"
 	"github.com/brads3290/cclogviewer/internal/processor/tools/diff"
 	"github.com/brads3290/cclogviewer/internal/utils"
 )
@@ -24,11 +25,18 @@ func NewMultiEditFormatter() *MultiEditFormatter {
 
 // FormatInput formats the input for the MultiEdit tool
 func (f *MultiEditFormatter) FormatInput(data map[string]interface{}) (template.HTML, error) {
+	return f.FormatInputAtLines(data, nil)
+}
+
+// FormatInputAtLines formats the input for the MultiEdit tool, numbering each diff by the
+// file line its edit starts at
+func (f *MultiEditFormatter) FormatInputAtLines(data map[string]interface{}, lines []int) (template.HTML, error) {
 	edits := f.extractSlice(data, "edits")
 	if edits == nil || len(edits) == 0 {
 		return template.HTML("<div>No edits specified</div>"), nil
 	}
 
+	filePath := f.extractString(data, "file_path")
 	var result strings.Builder
 
 	// Process each edit
@@ -42,8 +50,9 @@ func (f *MultiEditFormatter) FormatInput(data map[string]interface{}) (template.
 		newString := f.extractString(edit, "new_string")
 		replaceAll := f.extractBool(edit, "replace_all")
 
-		// Compute the diff for this edit
-		diffLines := diff.ComputeLineDiff(oldString, newString)
+		// Compute the diff for this edit, highlighting the changed words of modified lines
+		diffLines := diff.HighlightChanges(diff.ComputeLineDiff(oldString, newString))
+		diffLines = diff.HighlightSyntax(diffLines, filePath)
 
 		// Add separator between edits
 		if i > 0 {
@@ -58,12 +67,18 @@ func (f *MultiEditFormatter) FormatInput(data map[string]interface{}) (template.
 		result.WriteString(`</div>`)
 
 		// Add the diff
-		result.WriteString(string(diff.FormatDiffHTML(diffLines)))
+		result.WriteString(string(formatPlacedDiff(diffLines, lines, i)))
 	}
 
 	return template.HTML(result.String()), nil
 }
 
+// FormatOutput shows the result of the MultiEdit tool only if it failed, since the diff of the
+// input already shows the change
+func (f *MultiEditFormatter) FormatOutput(data map[string]interface{}, output *tools.ToolOutput) (template.HTML, error) {
+	return f.formatErrorOutput(output), nil
+}
+
 // ValidateInput validates the input for the MultiEdit tool
 func (f *MultiEditFormatter) ValidateInput(data map[string]interface{}) error {
 	if err := utils.ValidateRequiredField(data, "file_path"); err != nil {
@@ -0,0 +1,249 @@
+package formatters
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/brads3290/cclogviewer/internal/constants"
+	"github.com/brads3290/cclogviewer/internal/highlight"
+	"github.com/brads3290/cclogviewer/internal/markdown"
+	"github.com/brads3290/cclogviewer/internal/processor/tools"
+	"github.com/brads3290/cclogviewer/internal/utils"
+)
+
+var (
+	// notebookCellPattern matches a cell of a notebook in the text of a Read result
+	notebookCellPattern = regexp.MustCompile(`(?s)<cell id="([^"]*)">(.*?)</cell id="[^"]*">`)
+	// notebookCellTypePattern matches the tag starting the source of a cell that isn't code
+	notebookCellTypePattern = regexp.MustCompile(`^<cell_type>([^<]*)</cell_type>`)
+	// notebookCellIndexPattern matches the cell IDs the tools give cells that have none
+	notebookCellIndexPattern = regexp.MustCompile(`^cell-(\d+)$`)
+)
+
+// notebookCell is a cell of a Jupyter notebook
+type notebookCell struct {
+	id             string
+	cellType       string
+	source         string
+	executionCount int      // 0 if the cell hasn't run
+	outputs        []string // Text of the outputs of a code cell
+}
+
+// isNotebookPath reports whether a file is a Jupyter notebook
+func isNotebookPath(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), constants.NotebookExtension)
+}
+
+// parseNotebookText parses the cells of a notebook from the text of a Read result, where
+// each cell is wrapped in a cell tag and cells other than code start with their type
+func parseNotebookText(content string) []notebookCell {
+	var cells []notebookCell
+	for _, match := range notebookCellPattern.FindAllStringSubmatch(content, -1) {
+		cell := notebookCell{id: match[1], cellType: "code", source: match[2]}
+		if typeMatch := notebookCellTypePattern.FindStringSubmatch(cell.source); typeMatch != nil {
+			cell.cellType = typeMatch[1]
+			cell.source = cell.source[len(typeMatch[0]):]
+		}
+		cell.source = strings.Trim(cell.source, "\n")
+		cells = append(cells, cell)
+	}
+	return cells
+}
+
+// notebookReadCells returns the cells in the structured result of a Read call of a
+// notebook, with the language of its code cells
+func notebookReadCells(toolUseResult interface{}) ([]notebookCell, string) {
+	payload, ok := toolUseResult.(map[string]interface{})
+	if !ok {
+		return nil, ""
+	}
+	file := utils.ExtractMap(payload, "file")
+	if file == nil {
+		return nil, ""
+	}
+
+	var cells []notebookCell
+	language := ""
+	for _, item := range utils.ExtractSlice(file, "cells") {
+		cellMap, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		cell := notebookCell{
+			id:             utils.ExtractString(cellMap, "cell_id"),
+			cellType:       utils.ExtractString(cellMap, "cellType"),
+			source:         notebookText(cellMap["source"]),
+			executionCount: utils.ExtractInt(cellMap, "execution_count"),
+			outputs:        notebookOutputs(utils.ExtractSlice(cellMap, "outputs")),
+		}
+		if language == "" {
+			language = utils.ExtractString(cellMap, "language")
+		}
+		cells = append(cells, cell)
+	}
+	return cells, language
+}
+
+// parseNotebookJSON parses the cells of an .ipynb document, with the language of its code
+// cells. It returns false if the document isn't a notebook.
+func parseNotebookJSON(doc string) ([]notebookCell, string, bool) {
+	var notebook map[string]interface{}
+	if err := json.Unmarshal([]byte(doc), &notebook); err != nil {
+		return nil, "", false
+	}
+	items, ok := notebook["cells"].([]interface{})
+	if !ok {
+		return nil, "", false
+	}
+
+	metadata := utils.ExtractMap(notebook, "metadata")
+	language := utils.ExtractString(utils.ExtractMap(metadata, "language_info"), "name")
+	if language == "" {
+		language = utils.ExtractString(utils.ExtractMap(metadata, "kernelspec"), "language")
+	}
+
+	cells := make([]notebookCell, 0, len(items))
+	for i, item := range items {
+		cellMap, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		cell := notebookCell{
+			id:             utils.ExtractString(cellMap, "id"),
+			cellType:       utils.ExtractString(cellMap, "cell_type"),
+			source:         notebookText(cellMap["source"]),
+			executionCount: utils.ExtractInt(cellMap, "execution_count"),
+			outputs:        notebookOutputs(utils.ExtractSlice(cellMap, "outputs")),
+		}
+		if cell.id == "" {
+			cell.id = fmt.Sprintf("cell-%d", i)
+		}
+		cells = append(cells, cell)
+	}
+	return cells, language, true
+}
+
+// findNotebookCell finds a cell by its ID, falling back to the index in IDs like "cell-3"
+// that the tools give cells without one
+func findNotebookCell(cells []notebookCell, id string) (notebookCell, bool) {
+	for _, cell := range cells {
+		if cell.id == id {
+			return cell, true
+		}
+	}
+	if match := notebookCellIndexPattern.FindStringSubmatch(id); match != nil {
+		if i, err := strconv.Atoi(match[1]); err == nil && i < len(cells) {
+			return cells[i], true
+		}
+	}
+	return notebookCell{}, false
+}
+
+// notebookText returns the text of a cell source or output, which .ipynb files store as
+// either a string or a list of lines
+func notebookText(v interface{}) string {
+	switch value := v.(type) {
+	case string:
+		return value
+	case []interface{}:
+		var sb strings.Builder
+		for _, line := range value {
+			if s, ok := line.(string); ok {
+				sb.WriteString(s)
+			}
+		}
+		return sb.String()
+	default:
+		return ""
+	}
+}
+
+// notebookOutputs returns the text of the outputs of a code cell. Outputs without text,
+// like images, are skipped.
+func notebookOutputs(items []interface{}) []string {
+	var outputs []string
+	for _, item := range items {
+		output, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		var text string
+		switch utils.ExtractString(output, "output_type") {
+		case "execute_result", "display_data":
+			text = notebookText(utils.ExtractMap(output, "data")["text/plain"])
+		case "error":
+			text = strings.Join(stringSlice(utils.ExtractSlice(output, "traceback")), "\n")
+			if text == "" {
+				text = utils.ExtractString(output, "ename") + ": " + utils.ExtractString(output, "evalue")
+			}
+		}
+		if text == "" {
+			text = notebookText(output["text"])
+		}
+		if text = strings.TrimRight(text, "\n"); text != "" {
+			outputs = append(outputs, text)
+		}
+	}
+	return outputs
+}
+
+// formatNotebookCell formats a notebook cell: code cells are highlighted in the notebook's
+// language and followed by their outputs, and Markdown cells are rendered
+func formatNotebookCell(cell notebookCell, language string) string {
+	cellType := cell.cellType
+	if cellType == "" {
+		cellType = "code"
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf(`<div class="notebook-cell %s">`, utils.EscapeHTML(cellType)))
+	sb.WriteString(`<div class="notebook-cell-header">`)
+	if cellType == "code" {
+		prompt := " "
+		if cell.executionCount > 0 {
+			prompt = strconv.Itoa(cell.executionCount)
+		}
+		sb.WriteString(fmt.Sprintf(`<span class="notebook-cell-prompt">[%s]</span>`, prompt))
+	}
+	if cell.id != "" {
+		sb.WriteString(fmt.Sprintf(`<span class="notebook-cell-id">%s</span>`, utils.EscapeHTML(cell.id)))
+	}
+	sb.WriteString(fmt.Sprintf(`<span class="notebook-cell-type">%s</span>`, utils.EscapeHTML(cellType)))
+	sb.WriteString(`</div>`)
+
+	switch cellType {
+	case "code":
+		if language == "" {
+			language = constants.NotebookDefaultLanguage
+		}
+		sb.WriteString(fmt.Sprintf(`<pre class="notebook-cell-source">%s</pre>`, highlight.LanguageCode(language, cell.source)))
+	case "markdown":
+		sb.WriteString(`<div class="notebook-cell-source markdown">`)
+		sb.WriteString(markdown.Render(cell.source))
+		sb.WriteString(`</div>`)
+	default:
+		sb.WriteString(fmt.Sprintf(`<pre class="notebook-cell-source">%s</pre>`, utils.EscapeHTML(cell.source)))
+	}
+
+	for _, output := range cell.outputs {
+		sb.WriteString(fmt.Sprintf(`<pre class="notebook-cell-output">%s</pre>`, tools.ConvertANSIToHTML(output)))
+	}
+	sb.WriteString(`</div>`)
+	return sb.String()
+}
+
+// formatNotebook formats the cells of a notebook
+func formatNotebook(cells []notebookCell, language string) string {
+	var sb strings.Builder
+	sb.WriteString(`<div class="notebook">`)
+	for _, cell := range cells {
+		sb.WriteString(formatNotebookCell(cell, language))
+	}
+	sb.WriteString(`</div>`)
+	return sb.String()
+}
@@ -0,0 +1,155 @@
+package formatters
+
+import (
+	"fmt"
+	"html/template"
+	"strings"
+
+	"github.com/brads3290/cclogviewer/internal/constants"
+	"github.com/brads3290/cclogviewer/internal/processor/tools"
+	"github.com/brads3290/cclogviewer/internal/processor/tools/diff"
+	"github.com/brads3290/cclogviewer/internal/utils"
+)
+
+// NotebookEditFormatter formats NotebookEdit tool inputs and outputs.
+type NotebookEditFormatter struct {
+	BaseFormatter
+}
+
+// NewNotebookEditFormatter creates a new NotebookEdit formatter
+func NewNotebookEditFormatter() *NotebookEditFormatter {
+	return &NotebookEditFormatter{
+		BaseFormatter: BaseFormatter{toolName: constants.ToolNameNotebookEdit},
+	}
+}
+
+// FormatInput formats the input for the NotebookEdit tool: the notebook, the cell and how
+// it is edited, then the new source of the cell
+func (f *NotebookEditFormatter) FormatInput(data map[string]interface{}) (template.HTML, error) {
+	mode := f.editMode(data)
+	cellLabel := "cell"
+	if mode == constants.NotebookEditModeInsert {
+		cellLabel = "after"
+	}
+
+	var sb strings.Builder
+	sb.WriteString(`<div class="search-header">`)
+	sb.WriteString(`<span class="search-icon">📓</span>`)
+	sb.WriteString(formatFileLink(f.extractString(data, "notebook_path"), ""))
+	sb.WriteString(formatSearchFields([]searchField{
+		{label: cellLabel, value: f.cellRef(data)},
+		{label: "type", value: f.extractString(data, "cell_type")},
+	}))
+	sb.WriteString(fmt.Sprintf(`<span class="notebook-edit-mode %s">%s</span>`, f.escapeHTML(mode), f.escapeHTML(mode)))
+	sb.WriteString(`</div>`)
+
+	if mode == constants.NotebookEditModeDelete {
+		sb.WriteString(`<div class="search-note">Cell deleted</div>`)
+	} else {
+		sb.WriteString(formatNotebookCell(notebookCell{
+			cellType: f.extractString(data, "cell_type"),
+			source:   f.extractString(data, "new_source"),
+		}, ""))
+	}
+
+	return template.HTML(sb.String()), nil
+}
+
+// FormatOutput shows how a replaced cell changed, when the log holds the notebook before
+// the edit. Other results are only shown if the edit failed.
+func (f *NotebookEditFormatter) FormatOutput(data map[string]interface{}, output *tools.ToolOutput) (template.HTML, error) {
+	if output == nil || output.IsError || f.editMode(data) != constants.NotebookEditModeReplace {
+		return f.formatErrorOutput(output), nil
+	}
+
+	payload, ok := output.ToolUseResult.(map[string]interface{})
+	if !ok {
+		return template.HTML(""), nil
+	}
+	if message := utils.ExtractString(payload, "error"); message != "" {
+		return tools.FormatResultSection(tools.FormatText(message)), nil
+	}
+
+	cells, language, ok := parseNotebookJSON(utils.ExtractString(payload, "original_file"))
+	if !ok {
+		return template.HTML(""), nil
+	}
+	cellID := utils.ExtractString(payload, "cell_id")
+	if cellID == "" {
+		cellID = f.extractString(data, "cell_id")
+	}
+	if _, ok := data["cell_number"]; ok && cellID == "" {
+		cellID = fmt.Sprintf("cell-%d", f.extractInt(data, "cell_number"))
+	}
+	cell, found := findNotebookCell(cells, cellID)
+	newSource := f.extractString(data, "new_source")
+	if !found || cell.source == newSource {
+		return template.HTML(""), nil
+	}
+
+	if lang := utils.ExtractString(payload, "language"); lang != "" {
+		language = lang
+	}
+	cellType := f.extractString(data, "cell_type")
+	if cellType == "" {
+		cellType = cell.cellType
+	}
+	switch {
+	case cellType == "markdown":
+		language = "markdown"
+	case language == "":
+		language = constants.NotebookDefaultLanguage
+	}
+
+	diffLines := diff.HighlightChanges(diff.ComputeLineDiff(cell.source, newSource))
+	diffLines = diff.HighlightLanguage(diffLines, language)
+
+	var sb strings.Builder
+	sb.WriteString(`<div class="notebook-diff">`)
+	sb.WriteString(formatSearchSummary("Changes to " + cellID))
+	sb.WriteString(string(diff.FormatDiffHTML(diffLines)))
+	sb.WriteString(`</div>`)
+	return template.HTML(sb.String()), nil
+}
+
+// ValidateInput validates the input for the NotebookEdit tool
+func (f *NotebookEditFormatter) ValidateInput(data map[string]interface{}) error {
+	return utils.ValidateRequiredField(data, "notebook_path")
+}
+
+// GetDescription returns the notebook and the cell edited
+func (f *NotebookEditFormatter) GetDescription(data map[string]interface{}) string {
+	desc := f.extractString(data, "notebook_path")
+	mode := f.editMode(data)
+	cell := f.cellRef(data)
+
+	switch {
+	case mode == constants.NotebookEditModeInsert && cell != "":
+		desc += fmt.Sprintf(" (insert after %s)", cell)
+	case mode == constants.NotebookEditModeInsert:
+		desc += " (insert at start)"
+	case cell != "":
+		desc += fmt.Sprintf(" (%s %s)", mode, cell)
+	}
+	return desc
+}
+
+// editMode returns the edit mode of a NotebookEdit call
+func (f *NotebookEditFormatter) editMode(data map[string]interface{}) string {
+	if mode := f.extractString(data, "edit_mode"); mode != "" {
+		return mode
+	}
+	return constants.NotebookEditModeReplace
+}
+
+// cellRef returns the cell a NotebookEdit call names, by ID or, in older logs, by its
+// 0-based number
+func (f *NotebookEditFormatter) cellRef(data map[string]interface{}) string {
+	if id := f.extractString(data, "cell_id"); id != "" {
+		return id
+	}
+	if _, ok := data["cell_number"]; ok {
+		return fmt.Sprintf("#%d", f.extractInt(data, "cell_number"))
+	}
+	return ""
+}
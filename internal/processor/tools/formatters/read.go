@@ -2,9 +2,13 @@ package formatters
 
 import (
 	"fmt"
+	"html"
 	"html/template"
+	"strings"
 
 	"github.com/brads3290/cclogviewer/internal/constants"
+	"github.com/brads3290/cclogviewer/internal/highlight"
+	"github.com/brads3290/cclogviewer/internal/processor/tools"
 	"github.com/brads3290/cclogviewer/internal/utils"
 )
 
@@ -27,6 +31,64 @@ func (f *ReadFormatter) FormatInput(data map[string]interface{}) (template.HTML,
 	return template.HTML(""), nil
 }
 
+// FormatOutput shows the file content of a Read result with its line numbers, highlighting
+// the syntax of the file. Notebooks are shown as their cells.
+func (f *ReadFormatter) FormatOutput(data map[string]interface{}, output *tools.ToolOutput) (template.HTML, error) {
+	if output == nil {
+		return template.HTML(""), nil
+	}
+
+	filePath := f.extractString(data, "file_path")
+	if isNotebookPath(filePath) && !output.IsError {
+		cells, language := notebookReadCells(output.ToolUseResult)
+		if len(cells) == 0 {
+			cells = parseNotebookText(output.Content)
+		}
+		if len(cells) > 0 {
+			return template.HTML(formatNotebook(cells, language)), nil
+		}
+	}
+
+	if output.Content == "" {
+		return template.HTML(""), nil
+	}
+
+	highlighter := highlight.ForFile(filePath)
+	var result strings.Builder
+
+	result.WriteString(`<div class="read-content">`)
+	result.WriteString(`<div class="read-code">`)
+
+	for _, line := range strings.Split(output.Content, "\n") {
+		// Extract line number from the format: "   123→content"
+		lineNum := ""
+		lineContent := line
+		if idx := strings.Index(line, "→"); idx > 0 {
+			lineNum = strings.TrimSpace(line[:idx])
+			lineContent = line[idx+len("→"):]
+		}
+
+		result.WriteString(`<div class="read-line">`)
+		if lineNum != "" {
+			result.WriteString(fmt.Sprintf(`<span class="line-number">%s</span>`, html.EscapeString(lineNum)))
+		}
+		// Use separate span for content to enable proper wrapping
+		result.WriteString(`<span class="line-content">`)
+		if lineNum != "" {
+			result.WriteString(highlighter.LineHTML(lineContent))
+		} else {
+			result.WriteString(html.EscapeString(lineContent))
+		}
+		result.WriteString(`</span>`)
+		result.WriteString(`</div>`)
+	}
+
+	result.WriteString(`</div>`)
+	result.WriteString(`</div>`)
+
+	return template.HTML(result.String()), nil
+}
+
 // ValidateInput validates the input for the Read tool
 func (f *ReadFormatter) ValidateInput(data map[string]interface{}) error {
 	return utils.ValidateRequiredField(data, "file_path")
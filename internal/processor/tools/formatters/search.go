@@ -0,0 +1,171 @@
+package formatters
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/brads3290/cclogviewer/internal/utils"
+)
+
+// foundCountPattern matches the count line search tools put before their results
+var foundCountPattern = regexp.MustCompile(`^Found \d+ (?:files?|matches|total occurrences)`)
+
+// searchField is a labeled value shown in the header of a search tool
+type searchField struct {
+	label string
+	value string
+}
+
+// formatSearchHeader formats the header of a search tool: an icon, the pattern searched for
+// and the fields that are set
+func formatSearchHeader(icon, pattern string, fields []searchField) string {
+	var sb strings.Builder
+	sb.WriteString(`<div class="search-header">`)
+	sb.WriteString(fmt.Sprintf(`<span class="search-icon">%s</span>`, icon))
+	if pattern != "" {
+		sb.WriteString(fmt.Sprintf(`<code class="search-pattern">%s</code>`, utils.EscapeHTML(pattern)))
+	}
+	sb.WriteString(formatSearchFields(fields))
+	sb.WriteString(`</div>`)
+	return sb.String()
+}
+
+// formatSearchFields formats the labeled fields of a tool header that are set
+func formatSearchFields(fields []searchField) string {
+	var sb strings.Builder
+	for _, field := range fields {
+		if field.value == "" {
+			continue
+		}
+		sb.WriteString(fmt.Sprintf(`<span class="search-field"><span class="search-field-label">%s</span> %s</span>`,
+			utils.EscapeHTML(field.label), utils.EscapeHTML(field.value)))
+	}
+	return sb.String()
+}
+
+// formatSearchSummary formats the line counting the results of a search
+func formatSearchSummary(summary string) string {
+	return fmt.Sprintf(`<div class="search-summary">%s</div>`, utils.EscapeHTML(summary))
+}
+
+// formatSearchNotes formats the lines of a search result that aren't results, such as
+// "No files found" or a truncation notice
+func formatSearchNotes(notes []string) string {
+	var sb strings.Builder
+	for _, note := range notes {
+		sb.WriteString(fmt.Sprintf(`<div class="search-note">%s</div>`, utils.EscapeHTML(note)))
+	}
+	return sb.String()
+}
+
+// formatFileLink formats a link to a file found by a search. Paths are shown relative to
+// the directory searched when they are inside it.
+func formatFileLink(path, base string) string {
+	display := path
+	if base != "" && filepath.IsAbs(path) {
+		if rel, err := filepath.Rel(base, path); err == nil && !strings.HasPrefix(rel, "..") {
+			display = rel
+		}
+		if display == "." {
+			// The base is the file itself, like in a Grep of a single file
+			display = filepath.Base(path)
+		}
+	}
+
+	target := path
+	if !filepath.IsAbs(target) && filepath.IsAbs(base) {
+		target = filepath.Join(base, target)
+	}
+	if !filepath.IsAbs(target) {
+		return fmt.Sprintf(`<span class="search-file" title="%s">%s</span>`,
+			utils.EscapeHTML(path), utils.EscapeHTML(display))
+	}
+
+	href := (&url.URL{Scheme: "file", Path: target}).String()
+	return fmt.Sprintf(`<a class="search-file" href="%s" target="_blank" rel="noopener noreferrer" title="%s">%s</a>`,
+		utils.EscapeHTML(href), utils.EscapeHTML(target), utils.EscapeHTML(display))
+}
+
+// formatFileList formats the files found by a search as a list of links
+func formatFileList(paths []string, base string) string {
+	var sb strings.Builder
+	sb.WriteString(`<ul class="search-file-list">`)
+	for _, path := range paths {
+		sb.WriteString(`<li>`)
+		sb.WriteString(formatFileLink(path, base))
+		sb.WriteString(`</li>`)
+	}
+	sb.WriteString(`</ul>`)
+	return sb.String()
+}
+
+// formatFileResults formats the files found by a search with their count, followed by the
+// notes of the result
+func formatFileResults(paths, notes []string, base string, truncated bool) string {
+	var sb strings.Builder
+	sb.WriteString(`<div class="search-results">`)
+	if len(paths) > 0 || len(notes) == 0 {
+		summary := countNoun(len(paths), "file", "files")
+		if truncated {
+			summary += " (truncated)"
+		}
+		sb.WriteString(formatSearchSummary(summary))
+	}
+	if len(paths) > 0 {
+		sb.WriteString(formatFileList(paths, base))
+	}
+	sb.WriteString(formatSearchNotes(notes))
+	sb.WriteString(`</div>`)
+	return sb.String()
+}
+
+// parseFileList splits the text of a search result listing one file per line into the
+// files and the other lines, such as "No files found"
+func parseFileList(content string) (paths []string, notes []string) {
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "", foundCountPattern.MatchString(line):
+			// Counts are computed from the list itself
+		case strings.HasPrefix(line, "No ") || strings.HasPrefix(line, "(") || strings.HasPrefix(line, "["):
+			notes = append(notes, line)
+		default:
+			paths = append(paths, line)
+		}
+	}
+	return paths, notes
+}
+
+// stringSlice returns the string elements of a list value
+func stringSlice(values []interface{}) []string {
+	var result []string
+	for _, value := range values {
+		if s, ok := value.(string); ok {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+// countNoun formats a count with the singular or plural form of a noun
+func countNoun(n int, singular, plural string) string {
+	if n == 1 {
+		return fmt.Sprintf("1 %s", singular)
+	}
+	return fmt.Sprintf("%d %s", n, plural)
+}
+
+// searchBase returns the directory paths of a search are relative to: the path searched,
+// or the working directory of the call
+func searchBase(path, cwd string) string {
+	if path == "" {
+		return cwd
+	}
+	if !filepath.IsAbs(path) && cwd != "" {
+		return filepath.Join(cwd, path)
+	}
+	return path
+}
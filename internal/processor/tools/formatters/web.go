@@ -0,0 +1,92 @@
+package formatters
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/brads3290/cclogviewer/internal/markdown"
+	"github.com/brads3290/cclogviewer/internal/utils"
+)
+
+// webSource is a web page a web tool used, shown as a source card
+type webSource struct {
+	title   string
+	url     string
+	snippet string
+	meta    string // Extra details, such as the HTTP status of a fetch
+}
+
+// webURL parses a URL of a web tool, returning nil unless it is an http or https URL
+func webURL(raw string) *url.URL {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || u.Host == "" {
+		return nil
+	}
+	if scheme := strings.ToLower(u.Scheme); scheme != "http" && scheme != "https" {
+		return nil
+	}
+	return u
+}
+
+// webDomain returns the domain of a URL without its www. prefix
+func webDomain(u *url.URL) string {
+	return strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+}
+
+// formatWebLink formats a link that opens in a new tab. Only http and https URLs are linked;
+// anything else is shown as text.
+func formatWebLink(class, rawURL, text string) string {
+	u := webURL(rawURL)
+	if u == nil {
+		return fmt.Sprintf(`<span class="%s">%s</span>`, class, utils.EscapeHTML(text))
+	}
+	return fmt.Sprintf(`<a class="%s" href="%s" target="_blank" rel="noopener noreferrer">%s</a>`,
+		class, utils.EscapeHTML(u.String()), utils.EscapeHTML(text))
+}
+
+// formatSourceCards formats web pages as cards with their title, domain, URL and snippet.
+// Cards only hold text and links, so the page fetches nothing when it loads.
+func formatSourceCards(sources []webSource) string {
+	var sb strings.Builder
+	sb.WriteString(`<div class="source-cards">`)
+	for _, source := range sources {
+		domain := ""
+		if u := webURL(source.url); u != nil {
+			domain = webDomain(u)
+		}
+		title := source.title
+		if title == "" {
+			title = domain
+		}
+		if title == "" {
+			title = source.url
+		}
+
+		sb.WriteString(`<div class="source-card">`)
+		sb.WriteString(formatWebLink("source-title", source.url, title))
+		if domain != "" {
+			sb.WriteString(fmt.Sprintf(`<div class="source-domain">%s</div>`, utils.EscapeHTML(domain)))
+		}
+		sb.WriteString(fmt.Sprintf(`<div class="source-url">%s</div>`, utils.EscapeHTML(source.url)))
+		if source.meta != "" {
+			sb.WriteString(fmt.Sprintf(`<div class="source-meta">%s</div>`, utils.EscapeHTML(source.meta)))
+		}
+		if source.snippet != "" {
+			sb.WriteString(fmt.Sprintf(`<div class="source-snippet">%s</div>`, utils.EscapeHTML(source.snippet)))
+		}
+		sb.WriteString(`</div>`)
+	}
+	sb.WriteString(`</div>`)
+	return sb.String()
+}
+
+// formatWebText formats the text a web tool returned, which is usually Markdown written by
+// the model that read the pages
+func formatWebText(text string) string {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return ""
+	}
+	return `<div class="markdown web-text">` + markdown.Render(text) + `</div>`
+}
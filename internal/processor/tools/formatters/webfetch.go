@@ -0,0 +1,116 @@
+package formatters
+
+import (
+	"fmt"
+	"html/template"
+	"strings"
+
+	"github.com/brads3290/cclogviewer/internal/constants"
+	"github.com/brads3290/cclogviewer/internal/processor/tools"
+	"github.com/brads3290/cclogviewer/internal/utils"
+)
+
+// webFetchRedirectPrefix starts the line of a WebFetch result naming the URL a page redirects to
+const webFetchRedirectPrefix = "Redirect URL:"
+
+// WebFetchFormatter formats WebFetch tool inputs and outputs.
+type WebFetchFormatter struct {
+	BaseFormatter
+}
+
+// NewWebFetchFormatter creates a new WebFetch formatter
+func NewWebFetchFormatter() *WebFetchFormatter {
+	return &WebFetchFormatter{
+		BaseFormatter: BaseFormatter{toolName: constants.ToolNameWebFetch},
+	}
+}
+
+// FormatInput formats the input for the WebFetch tool: the URL and the prompt the page was
+// read with
+func (f *WebFetchFormatter) FormatInput(data map[string]interface{}) (template.HTML, error) {
+	rawURL := f.extractString(data, "url")
+	prompt := f.extractString(data, "prompt")
+
+	var sb strings.Builder
+	sb.WriteString(`<div class="search-header">`)
+	sb.WriteString(`<span class="search-icon">🌐</span>`)
+	sb.WriteString(formatWebLink("search-pattern", rawURL, rawURL))
+	sb.WriteString(`</div>`)
+	if prompt != "" {
+		sb.WriteString(fmt.Sprintf(`<div class="web-prompt"><span class="search-field-label">prompt</span> %s</div>`, f.escapeHTML(prompt)))
+	}
+	return template.HTML(sb.String()), nil
+}
+
+// FormatOutput shows the page the WebFetch tool read as a source card, followed by what
+// was found in it
+func (f *WebFetchFormatter) FormatOutput(data map[string]interface{}, output *tools.ToolOutput) (template.HTML, error) {
+	if output == nil || !output.HasResult || output.IsError {
+		return tools.FormatGenericOutput(output), nil
+	}
+
+	source := webSource{url: f.extractString(data, "url")}
+	text := output.Content
+	if payload, ok := output.ToolUseResult.(map[string]interface{}); ok {
+		if fetched := utils.ExtractString(payload, "url"); fetched != "" {
+			source.url = fetched
+		}
+		source.meta = fetchStatus(payload)
+		if result := utils.ExtractString(payload, "result"); result != "" && text == "" {
+			text = result
+		}
+	}
+
+	sources := []webSource{source}
+	for _, line := range strings.Split(text, "\n") {
+		if !strings.HasPrefix(line, webFetchRedirectPrefix) {
+			continue
+		}
+		if redirect := strings.TrimSpace(strings.TrimPrefix(line, webFetchRedirectPrefix)); redirect != "" {
+			sources = append(sources, webSource{url: redirect, meta: "redirect"})
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString(`<div class="search-results">`)
+	sb.WriteString(formatSourceCards(sources))
+	sb.WriteString(formatWebText(text))
+	sb.WriteString(`</div>`)
+
+	return template.HTML(sb.String()), nil
+}
+
+// ValidateInput validates the input for the WebFetch tool
+func (f *WebFetchFormatter) ValidateInput(data map[string]interface{}) error {
+	return utils.ValidateRequiredField(data, "url")
+}
+
+// GetDescription returns the URL fetched
+func (f *WebFetchFormatter) GetDescription(data map[string]interface{}) string {
+	return f.extractString(data, "url")
+}
+
+// fetchStatus describes the HTTP status and size of a fetched page from the structured
+// result of a WebFetch call
+func fetchStatus(payload map[string]interface{}) string {
+	var parts []string
+	if code := utils.ExtractInt(payload, "code"); code > 0 {
+		parts = append(parts, strings.TrimSpace(fmt.Sprintf("%d %s", code, utils.ExtractString(payload, "codeText"))))
+	}
+	if size := utils.ExtractInt(payload, "bytes"); size > 0 {
+		parts = append(parts, formatByteSize(size))
+	}
+	return strings.Join(parts, " · ")
+}
+
+// formatByteSize formats a size in bytes for display
+func formatByteSize(n int) string {
+	switch {
+	case n >= 1024*1024:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1024*1024))
+	case n >= 1024:
+		return fmt.Sprintf("%.1f KB", float64(n)/1024)
+	default:
+		return fmt.Sprintf("%d B", n)
+	}
+}
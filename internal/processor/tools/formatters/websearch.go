@@ -0,0 +1,163 @@
+package formatters
+
+import (
+	"encoding/json"
+	"html/template"
+	"strings"
+
+	"github.com/brads3290/cclogviewer/internal/constants"
+	"github.com/brads3290/cclogviewer/internal/processor/tools"
+	"github.com/brads3290/cclogviewer/internal/utils"
+)
+
+const (
+	// webSearchHeaderPrefix starts the first line of a WebSearch result
+	webSearchHeaderPrefix = "Web search results for query:"
+	// webSearchLinksPrefix starts a line of a WebSearch result listing the pages found as JSON
+	webSearchLinksPrefix = "Links:"
+)
+
+// WebSearchFormatter formats WebSearch tool inputs and outputs.
+type WebSearchFormatter struct {
+	BaseFormatter
+}
+
+// NewWebSearchFormatter creates a new WebSearch formatter
+func NewWebSearchFormatter() *WebSearchFormatter {
+	return &WebSearchFormatter{
+		BaseFormatter: BaseFormatter{toolName: constants.ToolNameWebSearch},
+	}
+}
+
+// FormatInput formats the input for the WebSearch tool
+func (f *WebSearchFormatter) FormatInput(data map[string]interface{}) (template.HTML, error) {
+	return template.HTML(formatSearchHeader("🌐", f.extractString(data, "query"), []searchField{
+		{label: "only", value: strings.Join(stringSlice(f.extractSlice(data, "allowed_domains")), ", ")},
+		{label: "except", value: strings.Join(stringSlice(f.extractSlice(data, "blocked_domains")), ", ")},
+	})), nil
+}
+
+// FormatOutput shows the pages the WebSearch tool found as source cards, followed by the
+// summary of the results
+func (f *WebSearchFormatter) FormatOutput(data map[string]interface{}, output *tools.ToolOutput) (template.HTML, error) {
+	if output == nil || !output.HasResult || output.IsError {
+		return tools.FormatGenericOutput(output), nil
+	}
+
+	sources, text := parseWebSearchResult(output.Content)
+	sources = mergeSources(sources, webSearchSources(output.ToolUseResult))
+
+	var sb strings.Builder
+	sb.WriteString(`<div class="search-results">`)
+	sb.WriteString(formatSearchSummary(countNoun(len(sources), "source", "sources")))
+	if len(sources) > 0 {
+		sb.WriteString(formatSourceCards(sources))
+	}
+	sb.WriteString(formatWebText(text))
+	sb.WriteString(`</div>`)
+
+	return template.HTML(sb.String()), nil
+}
+
+// ValidateInput validates the input for the WebSearch tool
+func (f *WebSearchFormatter) ValidateInput(data map[string]interface{}) error {
+	return utils.ValidateRequiredField(data, "query")
+}
+
+// GetDescription returns the search query
+func (f *WebSearchFormatter) GetDescription(data map[string]interface{}) string {
+	return f.extractString(data, "query")
+}
+
+// parseWebSearchResult splits the text of a WebSearch result into the pages of its Links
+// lines and the rest of the text
+func parseWebSearchResult(content string) ([]webSource, string) {
+	var sources []webSource
+	var text []string
+
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, webSearchHeaderPrefix):
+			// The query is already shown in the header
+		case strings.HasPrefix(trimmed, webSearchLinksPrefix):
+			var links []map[string]interface{}
+			if err := json.Unmarshal([]byte(strings.TrimSpace(strings.TrimPrefix(trimmed, webSearchLinksPrefix))), &links); err != nil {
+				text = append(text, line)
+				continue
+			}
+			for _, link := range links {
+				sources = append(sources, webSourceOf(link))
+			}
+		default:
+			text = append(text, line)
+		}
+	}
+
+	return sources, strings.Join(text, "\n")
+}
+
+// webSearchSources returns the pages listed in the structured result of a WebSearch call
+func webSearchSources(toolUseResult interface{}) []webSource {
+	payload, ok := toolUseResult.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	var sources []webSource
+	for _, result := range utils.ExtractSlice(payload, "results") {
+		resultMap, ok := result.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for _, item := range utils.ExtractSlice(resultMap, "content") {
+			if link, ok := item.(map[string]interface{}); ok {
+				sources = append(sources, webSourceOf(link))
+			}
+		}
+	}
+	return sources
+}
+
+// webSourceOf returns the page described by a search result link
+func webSourceOf(link map[string]interface{}) webSource {
+	snippet := utils.ExtractString(link, "snippet")
+	if snippet == "" {
+		snippet = utils.ExtractString(link, "description")
+	}
+	return webSource{
+		title:   utils.ExtractString(link, "title"),
+		url:     utils.ExtractString(link, "url"),
+		snippet: snippet,
+		meta:    utils.ExtractString(link, "page_age"),
+	}
+}
+
+// mergeSources adds the pages of b that aren't in a, by URL, filling in details a lacks
+func mergeSources(a, b []webSource) []webSource {
+	byURL := make(map[string]int, len(a))
+	var merged []webSource
+	all := make([]webSource, 0, len(a)+len(b))
+	all = append(append(all, a...), b...)
+	for _, source := range all {
+		if source.url == "" {
+			continue
+		}
+		i, seen := byURL[source.url]
+		if !seen {
+			byURL[source.url] = len(merged)
+			merged = append(merged, source)
+			continue
+		}
+		if merged[i].title == "" {
+			merged[i].title = source.title
+		}
+		if merged[i].snippet == "" {
+			merged[i].snippet = source.snippet
+		}
+		if merged[i].meta == "" {
+			merged[i].meta = source.meta
+		}
+	}
+	return merged
+}
@@ -29,7 +29,7 @@ func (f *WriteFormatter) FormatInput(data map[string]interface{}) (template.HTML
 	// Build the display
 	html := fmt.Sprintf(`<div class="write-content">`)
 	html += fmt.Sprintf(`<div class="write-header">Writing to: %s</div>`, f.formatPath(filePath))
-	html += fmt.Sprintf(`<div class="write-body">%s</div>`, f.formatCode(content))
+	html += fmt.Sprintf(`<div class="write-body">%s</div>`, f.formatCode(filePath, content))
 	html += `</div>`
 
 	return template.HTML(html), nil
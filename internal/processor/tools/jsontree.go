@@ -0,0 +1,168 @@
+package tools
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html"
+	"html/template"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/brads3290/cclogviewer/internal/constants"
+)
+
+// jsonContainer describes how objects or arrays are shown in a JSON tree
+type jsonContainer struct {
+	class string
+	open  string
+	close string
+	one   string // Noun counting a single member
+	many  string // Noun counting other numbers of members
+}
+
+var (
+	jsonObject = jsonContainer{class: "json-object", open: "{", close: "}", one: "key", many: "keys"}
+	jsonArray  = jsonContainer{class: "json-array", open: "[", close: "]", one: "item", many: "items"}
+)
+
+// FormatJSONTree formats a decoded JSON value as a tree whose objects and arrays can be
+// collapsed. Object keys are sorted, so a value is shown the same way every time.
+func FormatJSONTree(value interface{}) template.HTML {
+	var sb strings.Builder
+	sb.WriteString(`<div class="json-tree">`)
+	writeJSONNode(&sb, "", value, 0, false)
+	sb.WriteString(`</div>`)
+	return template.HTML(sb.String())
+}
+
+// ParseJSON decodes text that is a JSON object or array, keeping numbers as they were
+// written. It returns false for any other text.
+func ParseJSON(text string) (interface{}, bool) {
+	trimmed := strings.TrimSpace(text)
+	isObject := strings.HasPrefix(trimmed, "{") && strings.HasSuffix(trimmed, "}")
+	isArray := strings.HasPrefix(trimmed, "[") && strings.HasSuffix(trimmed, "]")
+	if !isObject && !isArray {
+		return nil, false
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(trimmed))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, false
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		// More than one value
+		return nil, false
+	}
+	return value, true
+}
+
+// writeJSONNode writes a value of a JSON tree, after the key it has in its object, if any
+func writeJSONNode(sb *strings.Builder, key string, value interface{}, depth int, comma bool) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		writeJSONContainer(sb, key, jsonObject, len(keys), depth, comma, func(i int) (string, interface{}) {
+			return keys[i], v[keys[i]]
+		})
+	case []interface{}:
+		writeJSONContainer(sb, key, jsonArray, len(v), depth, comma, func(i int) (string, interface{}) {
+			return "", v[i]
+		})
+	default:
+		sb.WriteString(`<div class="json-leaf">`)
+		writeJSONKey(sb, key)
+		sb.WriteString(formatJSONScalar(v))
+		if comma {
+			sb.WriteString(`<span class="json-punct">,</span>`)
+		}
+		sb.WriteString(`</div>`)
+	}
+}
+
+// writeJSONContainer writes an object or array of a JSON tree as a details element whose
+// summary counts its members. Only the first levels of the tree start expanded.
+func writeJSONContainer(sb *strings.Builder, key string, kind jsonContainer, n, depth int, comma bool, member func(i int) (string, interface{})) {
+	punct := kind.close
+	if comma {
+		punct += ","
+	}
+
+	if n == 0 {
+		sb.WriteString(`<div class="json-leaf">`)
+		writeJSONKey(sb, key)
+		sb.WriteString(fmt.Sprintf(`<span class="json-punct">%s%s</span>`, kind.open, html.EscapeString(punct)))
+		sb.WriteString(`</div>`)
+		return
+	}
+
+	openAttr := ""
+	if depth < constants.JSONTreeOpenDepth {
+		openAttr = " open"
+	}
+	noun := kind.many
+	if n == 1 {
+		noun = kind.one
+	}
+
+	sb.WriteString(fmt.Sprintf(`<details class="json-node %s"%s>`, kind.class, openAttr))
+	sb.WriteString(`<summary>`)
+	writeJSONKey(sb, key)
+	sb.WriteString(fmt.Sprintf(`<span class="json-punct">%s</span>`, kind.open))
+	sb.WriteString(fmt.Sprintf(`<span class="json-count">%d %s</span>`, n, noun))
+	sb.WriteString(`</summary>`)
+	sb.WriteString(`<div class="json-members">`)
+	for i := 0; i < n; i++ {
+		memberKey, value := member(i)
+		writeJSONNode(sb, memberKey, value, depth+1, i < n-1)
+	}
+	sb.WriteString(`</div>`)
+	sb.WriteString(fmt.Sprintf(`<span class="json-punct json-close">%s</span>`, html.EscapeString(punct)))
+	sb.WriteString(`</details>`)
+}
+
+// writeJSONKey writes the key of an object member
+func writeJSONKey(sb *strings.Builder, key string) {
+	if key == "" {
+		return
+	}
+	sb.WriteString(fmt.Sprintf(`<span class="hl-key">%s</span><span class="json-punct">: </span>`, html.EscapeString(quoteJSON(key))))
+}
+
+// formatJSONScalar formats a string, number, boolean or null of a JSON tree
+func formatJSONScalar(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return `<span class="hl-literal">null</span>`
+	case bool:
+		return fmt.Sprintf(`<span class="hl-literal">%t</span>`, v)
+	case json.Number:
+		return fmt.Sprintf(`<span class="hl-number">%s</span>`, html.EscapeString(v.String()))
+	case float64:
+		return fmt.Sprintf(`<span class="hl-number">%s</span>`, strconv.FormatFloat(v, 'f', -1, 64))
+	case string:
+		return fmt.Sprintf(`<span class="hl-string">%s</span>`, html.EscapeString(quoteJSON(v)))
+	default:
+		return html.EscapeString(fmt.Sprintf("%v", v))
+	}
+}
+
+// quoteJSON quotes a string as JSON without escaping HTML characters, which are escaped
+// separately
+func quoteJSON(s string) string {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(s); err != nil {
+		return strconv.Quote(s)
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
@@ -0,0 +1,21 @@
+package tools
+
+import (
+	"strings"
+
+	"github.com/brads3290/cclogviewer/internal/constants"
+)
+
+// SplitMCPToolName splits the name of an MCP tool, like mcp__github__create_issue, into
+// the server and tool names. It returns false for tools that aren't MCP tools.
+func SplitMCPToolName(name string) (server, tool string, ok bool) {
+	rest, found := strings.CutPrefix(name, constants.MCPToolPrefix)
+	if !found {
+		return "", "", false
+	}
+	server, tool, found = strings.Cut(rest, constants.MCPToolSeparator)
+	if !found || server == "" || tool == "" {
+		return "", "", false
+	}
+	return server, tool, true
+}
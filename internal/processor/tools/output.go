@@ -0,0 +1,94 @@
+package tools
+
+import (
+	"fmt"
+	"html"
+	"html/template"
+	"regexp"
+	"strings"
+
+	"github.com/brads3290/cclogviewer/internal/ansi"
+)
+
+var ansiConverter = ansi.NewANSIConverter()
+
+// ansiColorPattern matches an ANSI color escape sequence
+var ansiColorPattern = regexp.MustCompile(`\x1b\[\d+m`)
+
+// ToolOutput is the result of a tool call, as passed to FormatOutput.
+type ToolOutput struct {
+	HasResult     bool        // Whether the log holds a result for the call
+	Content       string      // Text of the result
+	IsError       bool        // Whether the call failed
+	ToolUseResult interface{} // Structured result of the call, if the log has one
+	CWD           string      // Working directory of the call
+}
+
+// ConvertANSIToHTML converts ANSI escape sequences to styled HTML, escaping the text.
+func ConvertANSIToHTML(input string) string {
+	converted, err := ansiConverter.ConvertToHTML(input)
+	if err != nil {
+		// Fallback to escaped text
+		return html.EscapeString(input)
+	}
+	return converted
+}
+
+// FormatText formats text for display, converting ANSI escape codes to styled HTML.
+// Text enclosed in square brackets, like [Request interrupted by user], is shown as a notice.
+func FormatText(content string) template.HTML {
+	trimmed := strings.TrimSpace(content)
+	if strings.HasPrefix(trimmed, "[") && strings.HasSuffix(trimmed, "]") && !ansiColorPattern.MatchString(content) {
+		stripped := trimmed[1 : len(trimmed)-1]
+		return template.HTML(fmt.Sprintf(`<span style="color: #999; font-style: italic;">%s</span>`, html.EscapeString(stripped)))
+	}
+
+	return template.HTML(strings.ReplaceAll(ConvertANSIToHTML(content), "\n", "<br>"))
+}
+
+// FormatResultSection wraps formatted result content in a collapsible Result section
+func FormatResultSection(content template.HTML) template.HTML {
+	var sb strings.Builder
+	sb.WriteString(`<div class="tool-result-section" style="margin-top: 15px;">`)
+	sb.WriteString(`<div class="result-header" style="cursor: pointer; user-select: none; display: flex; align-items: center; gap: 5px;">`)
+	sb.WriteString(`<svg class="result-expand-icon" width="16" height="16" viewBox="0 0 20 20" fill="currentColor" style="transition: transform 0.2s;">`)
+	sb.WriteString(`<path fill-rule="evenodd" d="M7.293 14.707a1 1 0 010-1.414L10.586 10 7.293 6.707a1 1 0 011.414-1.414l4 4a1 1 0 010 1.414l-4 4a1 1 0 01-1.414 0z" clip-rule="evenodd" />`)
+	sb.WriteString(`</svg>`)
+	sb.WriteString(`<strong>Result</strong>`)
+	sb.WriteString(`</div>`)
+	sb.WriteString(`<div class="result-content" style="display: none; margin-top: 10px;">`)
+	sb.WriteString(string(content))
+	sb.WriteString(`</div>`)
+	sb.WriteString(`</div>`)
+	return template.HTML(sb.String())
+}
+
+// FormatGenericOutput formats the result of a tool as text in a collapsible Result section.
+// Results that are JSON, or only structured, are shown as a JSON tree. It returns empty HTML
+// when the call has no result.
+func FormatGenericOutput(output *ToolOutput) template.HTML {
+	if output == nil || !output.HasResult {
+		return template.HTML("")
+	}
+	if !output.IsError {
+		if value, ok := ParseJSON(output.Content); ok {
+			return FormatResultSection(FormatJSONTree(value))
+		}
+		if strings.TrimSpace(output.Content) == "" && isStructured(output.ToolUseResult) {
+			return FormatResultSection(FormatJSONTree(output.ToolUseResult))
+		}
+	}
+	return FormatResultSection(FormatText(output.Content))
+}
+
+// isStructured reports whether a structured tool result is a non-empty object or array
+func isStructured(value interface{}) bool {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return len(v) > 0
+	case []interface{}:
+		return len(v) > 0
+	default:
+		return false
+	}
+}
@@ -3,20 +3,33 @@ package renderer
 import (
 	"fmt"
 	"github.com/brads3290/cclogviewer/internal/constants"
+	"github.com/brads3290/cclogviewer/internal/markdown"
 	"github.com/brads3290/cclogviewer/internal/models"
-	"github.com/brads3290/cclogviewer/internal/renderer/ansi"
-	"github.com/brads3290/cclogviewer/internal/renderer/builders"
-	"html"
+	"github.com/brads3290/cclogviewer/internal/processor/tools"
 	"html/template"
 	"os"
-	"regexp"
 	"strings"
 )
 
-var ansiConverter = ansi.NewANSIConverter()
+// Options controls how the HTML output is generated.
+type Options struct {
+	Debug        bool // Include debug logging in the page
+	HideThinking bool // Leave extended thinking blocks out of the page
+	RawMarkdown  bool // Show message text as written instead of rendering its Markdown
+}
 
 // GenerateHTML renders processed entries to an HTML file.
 func GenerateHTML(entries []*models.ProcessedEntry, outputFile string, debugMode bool) error {
+	return GenerateHTMLWithOptions(entries, outputFile, Options{Debug: debugMode})
+}
+
+// GenerateHTMLWithOptions renders processed entries to an HTML file using the given options.
+func GenerateHTMLWithOptions(entries []*models.ProcessedEntry, outputFile string, opts Options) error {
+	return GenerateSessionHTML(&models.Session{Entries: entries}, outputFile, opts)
+}
+
+// GenerateSessionHTML renders a processed session, including its title, to an HTML file.
+func GenerateSessionHTML(session *models.Session, outputFile string, opts Options) error {
 	// Create custom function map
 	funcMap := template.FuncMap{
 		"mul": func(a, b int) int {
@@ -46,26 +59,15 @@ func GenerateHTML(entries []*models.ProcessedEntry, outputFile string, debugMode
 			}
 			return result
 		},
-		"formatContent": func(content string) template.HTML {
-			// Check if content is enclosed in square brackets
+		"formatContent": tools.FormatText,
+		"formatMessage": func(content string) template.HTML {
+			// Message text is Markdown, unless it is terminal output or a bracketed notice
 			trimmed := strings.TrimSpace(content)
-			if strings.HasPrefix(trimmed, "[") && strings.HasSuffix(trimmed, "]") {
-				// Check if it's an ANSI escape sequence
-				if !regexp.MustCompile(`\x1b\[\d+m`).MatchString(content) {
-					// Regular bracketed message (like [Request interrupted by user])
-					stripped := trimmed[1 : len(trimmed)-1]
-					// Escape the content and wrap in styled span
-					return template.HTML(fmt.Sprintf(`<span style="color: #999; font-style: italic;">%s</span>`, html.EscapeString(stripped)))
-				}
+			if opts.RawMarkdown || strings.Contains(content, "\x1b[") ||
+				(strings.HasPrefix(trimmed, "[") && strings.HasSuffix(trimmed, "]")) {
+				return tools.FormatText(content)
 			}
-
-			// Convert ANSI escape codes to HTML (this handles escaping internally)
-			content = ConvertANSIToHTML(content)
-
-			// Convert newlines to <br>
-			content = strings.ReplaceAll(content, "\n", "<br>")
-
-			return template.HTML(content)
+			return template.HTML(`<div class="markdown">` + markdown.Render(content) + `</div>`)
 		},
 		"shortUUID": func(uuid string) string {
 			// Return first N characters of UUID for brevity
@@ -74,51 +76,21 @@ func GenerateHTML(entries []*models.ProcessedEntry, outputFile string, debugMode
 			}
 			return uuid
 		},
-		"formatReadResult": func(content string) template.HTML {
-			// Format Read tool results with line numbers
-			lines := strings.Split(content, "\n")
-			var result strings.Builder
-
-			result.WriteString(`<div class="read-content">`)
-			result.WriteString(`<div class="read-code">`)
-
-			for _, line := range lines {
-				// Extract line number from the format: "   123→content"
-				lineNum := ""
-				lineContent := line
-
-				if idx := strings.Index(line, "→"); idx > 0 {
-					lineNum = strings.TrimSpace(line[:idx])
-					// Get the content after the arrow, handling UTF-8 properly
-					runes := []rune(line)
-					arrowIdx := strings.Index(line, "→")
-					if arrowIdx >= 0 && arrowIdx+len("→") < len(line) {
-						lineContent = string(runes[len([]rune(line[:arrowIdx]))+1:])
-					} else {
-						lineContent = ""
-					}
-				}
-
-				result.WriteString(`<div class="read-line">`)
-				if lineNum != "" {
-					result.WriteString(fmt.Sprintf(`<span class="line-number">%s</span>`, html.EscapeString(lineNum)))
+		"imageSrc": func(image *models.ImageData) template.URL {
+			// Images are validated while processing, so the data URL is safe to embed
+			return template.URL("data:" + image.MediaType + ";base64," + image.Data)
+		},
+		"formatBytes": formatBytes,
+		"showThinking": func() bool {
+			return !opts.HideThinking
+		},
+		"hasVisibleBlocks": func(blocks []models.ContentBlock) bool {
+			for _, block := range blocks {
+				if !isThinkingBlock(block) || !opts.HideThinking {
+					return true
 				}
-				// Use separate span for content to enable proper wrapping
-				result.WriteString(`<span class="line-content">`)
-				escapedContent := html.EscapeString(lineContent)
-				result.WriteString(escapedContent)
-				result.WriteString(`</span>`)
-				result.WriteString(`</div>`)
 			}
-
-			result.WriteString(`</div>`)
-			result.WriteString(`</div>`)
-
-			return template.HTML(result.String())
-		},
-		"formatBashResult": func(toolCall interface{}) template.HTML {
-			formatter := NewBashResultFormatter()
-			return formatter.Format(toolCall)
+			return false
 		},
 	}
 
@@ -136,22 +108,38 @@ func GenerateHTML(entries []*models.ProcessedEntry, outputFile string, debugMode
 
 	// Create template data with entries and debug flag
 	data := struct {
+		Title   string
 		Entries []*models.ProcessedEntry
+		Files   []*models.FileHistory
 		Debug   bool
 	}{
-		Entries: entries,
-		Debug:   debugMode,
+		Title:   session.Title,
+		Entries: session.Entries,
+		Files:   session.Files,
+		Debug:   opts.Debug,
 	}
 
 	return ExecuteTemplate(tmpl, file, data)
 }
 
+// formatBytes formats a byte count for display
+func formatBytes(n int) string {
+	switch {
+	case n >= 1024*1024:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1024*1024))
+	case n >= 1024:
+		return fmt.Sprintf("%.1f KB", float64(n)/1024)
+	default:
+		return fmt.Sprintf("%d B", n)
+	}
+}
+
+// isThinkingBlock reports whether a content block holds extended thinking
+func isThinkingBlock(block models.ContentBlock) bool {
+	return block.Type == constants.ContentTypeThinking || block.Type == constants.ContentTypeRedactedThinking
+}
+
 // ConvertANSIToHTML converts ANSI escape sequences to styled HTML.
 func ConvertANSIToHTML(input string) string {
-	html, err := ansiConverter.ConvertToHTML(input)
-	if err != nil {
-		// Fallback to escaped text
-		return builders.EscapeHTML(input)
-	}
-	return html
+	return tools.ConvertANSIToHTML(input)
 }
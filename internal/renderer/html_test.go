@@ -3,10 +3,12 @@ package renderer
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/brads3290/cclogviewer/internal/ansi"
 	"github.com/brads3290/cclogviewer/internal/models"
-	"github.com/brads3290/cclogviewer/internal/renderer/ansi"
+	"github.com/brads3290/cclogviewer/internal/processor"
 	"github.com/brads3290/cclogviewer/internal/renderer/builders"
 	"github.com/brads3290/cclogviewer/internal/testutil"
 	"github.com/stretchr/testify/assert"
@@ -258,6 +260,7 @@ func TestRenderWithToolCalls(t *testing.T) {
 			},
 		},
 	}
+	processor.GetToolProcessor().FormatOutput(&entry.ToolCalls[0])
 
 	tmpfile := filepath.Join(t.TempDir(), "tools.html")
 	err := GenerateHTML([]*models.ProcessedEntry{entry}, tmpfile, false)
@@ -270,6 +273,58 @@ func TestRenderWithToolCalls(t *testing.T) {
 	assert.Contains(t, html, "Bash")
 	assert.Contains(t, html, "ls -la")
 	assert.Contains(t, html, "file1.txt")
+	assert.Contains(t, html, `class="inline-tool-container"`)
+	assert.NotContains(t, html, `class="tool-header"`)
+}
+
+func TestRenderReadResultHighlighting(t *testing.T) {
+	entry := testutil.CreateTestProcessedEntry(t, "message", "Reading")
+	entry.ToolCalls = []models.ToolCall{
+		{
+			ID:       "tool-1",
+			Name:     "Read",
+			RawInput: map[string]interface{}{"file_path": "/src/app.py"},
+			Result:   &models.ProcessedEntry{Content: "     1→def main():\n     2→    return None"},
+		},
+	}
+	processor.GetToolProcessor().FormatOutput(&entry.ToolCalls[0])
+
+	tmpfile := filepath.Join(t.TempDir(), "read.html")
+	err := GenerateHTML([]*models.ProcessedEntry{entry}, tmpfile, false)
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(tmpfile)
+	require.NoError(t, err)
+
+	html := string(content)
+	assert.Contains(t, html, `<span class="hl-keyword">def</span> <span class="hl-function">main</span>`)
+	assert.Contains(t, html, `<span class="hl-literal">None</span>`)
+	assert.Contains(t, html, ".hl-keyword {")
+}
+
+func TestRenderMCPToolCall(t *testing.T) {
+	entry := testutil.CreateTestProcessedEntry(t, "message", "Creating an issue")
+	toolCall := processor.GetToolProcessor().ProcessToolUseWithRegistry(map[string]interface{}{
+		"id":    "tool-1",
+		"name":  "mcp__github__create_issue",
+		"input": map[string]interface{}{"title": "Crash on start", "labels": []interface{}{"bug"}},
+	})
+	toolCall.Result = &models.ProcessedEntry{Content: `{"number": 42, "state": "open"}`}
+	entry.ToolCalls = []models.ToolCall{toolCall}
+	processor.GetToolProcessor().FormatOutput(&entry.ToolCalls[0])
+
+	tmpfile := filepath.Join(t.TempDir(), "mcp.html")
+	err := GenerateHTML([]*models.ProcessedEntry{entry}, tmpfile, false)
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(tmpfile)
+	require.NoError(t, err)
+
+	html := string(content)
+	assert.Contains(t, html, `<span class="mcp-server-badge" title="MCP server">github</span>`)
+	assert.Contains(t, html, `<span class="tool-name" title="mcp__github__create_issue">create_issue</span>`)
+	assert.Contains(t, html, `<span class="hl-string">&#34;bug&#34;</span>`)
+	assert.Contains(t, html, `<span class="hl-number">42</span>`)
 }
 
 func TestRenderErrorMessages(t *testing.T) {
@@ -318,4 +373,301 @@ func TestRenderFlatEntries(t *testing.T) {
 	// Verify depth styling is applied
 	assert.Contains(t, html, "depth-1")
 	assert.Contains(t, html, "depth-2")
-}
\ No newline at end of file
+}
+
+func TestRenderUnmatchedToolResults(t *testing.T) {
+	entry := testutil.CreateTestProcessedEntry(t, "user", "")
+	entry.IsToolResult = true
+	entry.ToolResults = []*models.ProcessedEntry{
+		{IsToolResult: true, ToolResultID: "tool-9", Content: "orphaned output", IsUnmatchedResult: true},
+		{IsToolResult: true, ToolResultID: "tool-1", Content: "matched output"},
+	}
+
+	tmpfile := filepath.Join(t.TempDir(), "unmatched.html")
+	err := GenerateHTML([]*models.ProcessedEntry{entry}, tmpfile, false)
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(tmpfile)
+	require.NoError(t, err)
+
+	html := string(content)
+	assert.Contains(t, html, "orphaned output")
+	assert.NotContains(t, html, "matched output")
+}
+
+func TestRenderContentBlocksInOrder(t *testing.T) {
+	entry := testutil.CreateTestProcessedEntry(t, "assistant", "Reading file.Editing file.")
+	entry.Role = "assistant"
+	entry.ToolCalls = []models.ToolCall{
+		{ID: "tool-read", Name: "Read"},
+		{ID: "tool-edit", Name: "Edit"},
+	}
+	entry.Blocks = []models.ContentBlock{
+		{Type: "text", Text: "Reading file."},
+		{Type: "tool_use", ToolCall: &entry.ToolCalls[0]},
+		{Type: "text", Text: "Editing file."},
+		{Type: "tool_use", ToolCall: &entry.ToolCalls[1]},
+	}
+
+	tmpfile := filepath.Join(t.TempDir(), "blocks.html")
+	err := GenerateHTML([]*models.ProcessedEntry{entry}, tmpfile, false)
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(tmpfile)
+	require.NoError(t, err)
+
+	html := string(content)
+	positions := []int{
+		strings.Index(html, "Reading file."),
+		strings.Index(html, `data-debug-id="tool-tool-read"`),
+		strings.Index(html, "Editing file."),
+		strings.Index(html, `data-debug-id="tool-tool-edit"`),
+	}
+	for i, pos := range positions {
+		require.NotEqual(t, -1, pos, "block %d not rendered", i)
+		if i > 0 {
+			assert.Greater(t, pos, positions[i-1], "block %d rendered out of order", i)
+		}
+	}
+	assert.Equal(t, 1, strings.Count(html, `data-debug-id="tool-tool-read"`), "tool call rendered twice")
+}
+
+func TestRenderThinkingBlocks(t *testing.T) {
+	newEntry := func() *models.ProcessedEntry {
+		entry := testutil.CreateTestProcessedEntry(t, "assistant", "")
+		entry.Role = "assistant"
+		entry.Blocks = []models.ContentBlock{
+			{Type: "thinking", Text: "secret reasoning"},
+		}
+		return entry
+	}
+
+	t.Run("shown by default", func(t *testing.T) {
+		tmpfile := filepath.Join(t.TempDir(), "thinking.html")
+		err := GenerateHTMLWithOptions([]*models.ProcessedEntry{newEntry()}, tmpfile, Options{})
+		require.NoError(t, err)
+
+		content, err := os.ReadFile(tmpfile)
+		require.NoError(t, err)
+		assert.Contains(t, string(content), `class="thinking-block"`)
+		assert.Contains(t, string(content), "secret reasoning")
+	})
+
+	t.Run("hidden when requested", func(t *testing.T) {
+		tmpfile := filepath.Join(t.TempDir(), "hidden.html")
+		err := GenerateHTMLWithOptions([]*models.ProcessedEntry{newEntry()}, tmpfile, Options{HideThinking: true})
+		require.NoError(t, err)
+
+		content, err := os.ReadFile(tmpfile)
+		require.NoError(t, err)
+		assert.NotContains(t, string(content), "secret reasoning")
+		assert.NotContains(t, string(content), `class="entry`, "thinking-only entry should not be rendered")
+	})
+}
+
+func TestRenderMarkdown(t *testing.T) {
+	newEntry := func() *models.ProcessedEntry {
+		entry := testutil.CreateTestProcessedEntry(t, "assistant", "")
+		entry.Role = "assistant"
+		entry.Blocks = []models.ContentBlock{
+			{Type: "text", Text: "## Plan\n\n- use **bold** text\n- call `run()`\n\n<script>alert(1)</script>"},
+		}
+		return entry
+	}
+
+	t.Run("rendered by default", func(t *testing.T) {
+		tmpfile := filepath.Join(t.TempDir(), "markdown.html")
+		err := GenerateHTMLWithOptions([]*models.ProcessedEntry{newEntry()}, tmpfile, Options{})
+		require.NoError(t, err)
+
+		content, err := os.ReadFile(tmpfile)
+		require.NoError(t, err)
+		assert.Contains(t, string(content), `<div class="markdown">`)
+		assert.Contains(t, string(content), "<h2>Plan</h2>")
+		assert.Contains(t, string(content), "<li>use <strong>bold</strong> text")
+		assert.Contains(t, string(content), "<code>run()</code>")
+		assert.Contains(t, string(content), "&lt;script&gt;alert(1)&lt;/script&gt;")
+		assert.NotContains(t, string(content), "<script>alert(1)</script>")
+	})
+
+	t.Run("raw when requested", func(t *testing.T) {
+		tmpfile := filepath.Join(t.TempDir(), "raw.html")
+		err := GenerateHTMLWithOptions([]*models.ProcessedEntry{newEntry()}, tmpfile, Options{RawMarkdown: true})
+		require.NoError(t, err)
+
+		content, err := os.ReadFile(tmpfile)
+		require.NoError(t, err)
+		assert.NotContains(t, string(content), `<div class="markdown">`)
+		assert.Contains(t, string(content), "## Plan")
+		assert.Contains(t, string(content), "**bold**")
+	})
+}
+
+func TestRenderImages(t *testing.T) {
+	entry := testutil.CreateTestProcessedEntry(t, "user", "Look at this")
+	entry.Role = "user"
+	entry.Blocks = []models.ContentBlock{
+		{Type: "text", Text: "Look at this"},
+		{Type: "image", Image: &models.ImageData{MediaType: "image/png", Data: "iVBORw0KGgo=", Size: 8}},
+		{Type: "image", Image: &models.ImageData{MediaType: "image/png", Size: 10 << 20, Omitted: true, OmitReason: "image too large"}},
+	}
+
+	tmpfile := filepath.Join(t.TempDir(), "images.html")
+	err := GenerateHTML([]*models.ProcessedEntry{entry}, tmpfile, false)
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(tmpfile)
+	require.NoError(t, err)
+
+	html := string(content)
+	assert.Contains(t, html, `src="data:image/png;base64,iVBORw0KGgo="`)
+	assert.Contains(t, html, "Image not shown: image too large (10.0 MB)")
+}
+
+func TestRenderInlineToolResultImages(t *testing.T) {
+	entry := testutil.CreateTestProcessedEntry(t, "message", "Taking a screenshot")
+	entry.ToolCalls = []models.ToolCall{
+		{
+			ID:       "tool-1",
+			Name:     "Bash",
+			RawInput: map[string]interface{}{"command": "screenshot"},
+			Result: &models.ProcessedEntry{
+				Content: "saved",
+				Blocks:  []models.ContentBlock{{Type: "image", Image: &models.ImageData{MediaType: "image/png", Data: "iVBORw0KGgo=", Size: 8}}},
+			},
+		},
+	}
+	processor.GetToolProcessor().FormatOutput(&entry.ToolCalls[0])
+
+	tmpfile := filepath.Join(t.TempDir(), "inline-images.html")
+	err := GenerateHTML([]*models.ProcessedEntry{entry}, tmpfile, false)
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(tmpfile)
+	require.NoError(t, err)
+
+	html := string(content)
+	assert.Contains(t, html, `class="inline-tool-container"`)
+	assert.Contains(t, html, `src="data:image/png;base64,iVBORw0KGgo="`)
+}
+
+func TestRenderSessionSummaries(t *testing.T) {
+	entry := testutil.CreateTestProcessedEntry(t, "assistant", "All done")
+	entry.Role = "assistant"
+	entry.Summaries = []models.Summary{{Text: "Fix login bug", LeafUUID: entry.UUID}}
+
+	session := &models.Session{
+		Title:     "Fix login bug",
+		Summaries: entry.Summaries,
+		Entries:   []*models.ProcessedEntry{entry},
+	}
+
+	tmpfile := filepath.Join(t.TempDir(), "summary.html")
+	err := GenerateSessionHTML(session, tmpfile, Options{})
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(tmpfile)
+	require.NoError(t, err)
+
+	html := string(content)
+	assert.Contains(t, html, "<title>Fix login bug - Claude Code Log Viewer</title>")
+	assert.Contains(t, html, "<h1>Fix login bug</h1>")
+	assert.Contains(t, html, `class="compaction-marker"`)
+}
+
+func TestRenderNonMessageEntries(t *testing.T) {
+	system := &models.ProcessedEntry{
+		UUID:   "sys-1",
+		Type:   "system",
+		System: &models.SystemInfo{Subtype: "api_error", Level: "error", Content: "Overloaded"},
+	}
+	snapshot := &models.ProcessedEntry{
+		Type:     "file-history-snapshot",
+		Snapshot: &models.FileSnapshot{MessageID: "msg-1", Files: []models.TrackedFile{{Path: "main.go", Version: 1}}},
+	}
+	unknown := &models.ProcessedEntry{
+		UUID:    "q-1",
+		Type:    "queue-operation",
+		RawJSON: "{\n  \"type\": \"queue-operation\"\n}",
+	}
+
+	tmpfile := filepath.Join(t.TempDir(), "meta.html")
+	err := GenerateHTML([]*models.ProcessedEntry{system, snapshot, unknown}, tmpfile, false)
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(tmpfile)
+	require.NoError(t, err)
+
+	html := string(content)
+	assert.Contains(t, html, "system-entry level-error")
+	assert.Contains(t, html, "Overloaded")
+	assert.Contains(t, html, "<code>main.go</code>")
+	assert.Contains(t, html, "Unknown entry: queue-operation")
+	assert.Contains(t, html, "&#34;type&#34;: &#34;queue-operation&#34;")
+}
+
+func TestRenderTaskLinkConfidence(t *testing.T) {
+	entry := testutil.CreateTestProcessedEntry(t, "assistant", "Delegating")
+	entry.Role = "assistant"
+	entry.ToolCalls = []models.ToolCall{
+		{
+			ID:             "task-1",
+			Name:           "Task",
+			Result:         &models.ProcessedEntry{Content: "Done"},
+			TaskEntries:    []*models.ProcessedEntry{testutil.CreateTestProcessedEntry(t, "assistant", "Subagent answer")},
+			LinkMethod:     "timestamp",
+			LinkConfidence: "medium",
+		},
+	}
+
+	tmpfile := filepath.Join(t.TempDir(), "task.html")
+	err := GenerateHTML([]*models.ProcessedEntry{entry}, tmpfile, false)
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(tmpfile)
+	require.NoError(t, err)
+
+	html := string(content)
+	assert.Contains(t, html, `class="link-badge confidence-medium"`)
+	assert.Contains(t, html, "Conversation linked by timestamp")
+}
+
+func TestRenderFileHistories(t *testing.T) {
+	entry := testutil.CreateTestProcessedEntry(t, "assistant", "Edited")
+	session := &models.Session{
+		Entries: []*models.ProcessedEntry{entry},
+		Files: []*models.FileHistory{
+			{
+				Path:           "/src/main.go",
+				InitialKnown:   true,
+				InitialContent: "a",
+				Operations: []models.FileOperation{
+					{ToolCallID: "read-1", Tool: "Read", Succeeded: true, Content: "a", ContentKnown: true},
+					{ToolCallID: "edit-1", Tool: "Edit", Succeeded: false, Content: "a", ContentKnown: true},
+					{ToolCallID: "edit-2", Tool: "Edit", Succeeded: true, IsSidechain: true, Content: "b", ContentKnown: true},
+				},
+				CumulativeDiff: `<div class="diff-content unified"></div>`,
+			},
+			{
+				Path:       "/src/other.go",
+				Operations: []models.FileOperation{{ToolCallID: "edit-3", Tool: "Edit", Succeeded: true}},
+			},
+		},
+	}
+
+	tmpfile := filepath.Join(t.TempDir(), "files.html")
+	err := GenerateSessionHTML(session, tmpfile, Options{})
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(tmpfile)
+	require.NoError(t, err)
+
+	html := string(content)
+	assert.Contains(t, html, `class="files-section"`)
+	assert.Contains(t, html, "2 files")
+	assert.Contains(t, html, `<code class="file-path">/src/main.go</code>`)
+	assert.Contains(t, html, `class="file-operation failed" data-tool-id="edit-1"`)
+	assert.Contains(t, html, `<span class="file-operation-note">subagent</span>`)
+	assert.Contains(t, html, `<div class="diff-content unified"></div>`)
+	assert.Contains(t, html, "The file content before the first change is not in the log.")
+}
@@ -102,6 +102,41 @@ document.addEventListener('click', (e) => {
         }
     }
     
+    // Handle thinking block header clicks
+    const thinkingHeader = e.target.closest('.thinking-header');
+    if (thinkingHeader) {
+        e.preventDefault();
+        e.stopPropagation();
+        const icon = thinkingHeader.querySelector('.thinking-expand-icon');
+        const content = thinkingHeader.nextElementSibling;
+        if (content) {
+            const isHidden = content.style.display === 'none';
+            content.style.display = isHidden ? 'block' : 'none';
+            icon.style.transform = isHidden ? 'rotate(90deg)' : 'rotate(0deg)';
+        }
+    }
+    
+    // Handle image thumbnail clicks
+    const thumbnail = e.target.closest('.image-thumbnail');
+    if (thumbnail) {
+        e.preventDefault();
+        e.stopPropagation();
+        thumbnail.classList.toggle('expanded');
+        thumbnail.title = thumbnail.classList.contains('expanded') ? 'Click to shrink' : 'Click to expand';
+    }
+    
+    // Handle folded unchanged diff lines
+    const foldToggle = e.target.closest('.diff-fold-toggle');
+    if (foldToggle) {
+        e.preventDefault();
+        e.stopPropagation();
+        const foldedLines = foldToggle.nextElementSibling;
+        if (foldedLines) {
+            foldedLines.style.display = 'block';
+            foldToggle.style.display = 'none';
+        }
+    }
+    
     // Handle caveat message header clicks
     const caveatHeader = e.target.closest('.caveat-header');
     if (caveatHeader) {
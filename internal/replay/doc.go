@@ -0,0 +1,2 @@
+// Package replay applies the file changes of a session to a directory.
+package replay
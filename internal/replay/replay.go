@@ -0,0 +1,189 @@
+package replay
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/brads3290/cclogviewer/internal/constants"
+	"github.com/brads3290/cclogviewer/internal/models"
+)
+
+// Step is the outcome of replaying a single Write, Edit or MultiEdit call.
+type Step struct {
+	Path       string // Path relative to the target directory, or the logged path if it has none
+	Tool       string
+	ToolCallID string
+	Timestamp  string
+	Conflict   string // Why the call couldn't be applied, empty if it was
+}
+
+// FileChange is the change replaying a session makes to a single file.
+type FileChange struct {
+	Path    string // Path relative to the target directory
+	Existed bool   // Whether the file existed before the replay
+	Before  string // Content before the replay
+	After   string // Content after the replay
+}
+
+// Result is the outcome of replaying a session.
+type Result struct {
+	Steps   []Step       // Replayed calls, grouped by file and in time order within a file
+	Changes []FileChange // Files whose content changed
+}
+
+// Conflicts returns the number of calls that couldn't be applied
+func (r *Result) Conflicts() int {
+	conflicts := 0
+	for _, step := range r.Steps {
+		if step.Conflict != "" {
+			conflicts++
+		}
+	}
+	return conflicts
+}
+
+// Replay applies the successful Write, Edit and MultiEdit calls of a session to the files
+// under dir. Paths are taken relative to the working directory of the calls. An edit whose
+// old_string isn't found exactly as the live tool requires is a conflict and is skipped.
+// In a dry run, files are read but never written.
+func Replay(files []*models.FileHistory, dir string, dryRun bool) (*Result, error) {
+	result := &Result{}
+
+	for _, file := range files {
+		if !file.Changed() {
+			continue
+		}
+
+		path, err := file.RelativePath()
+		if err != nil {
+			for _, op := range mutations(file) {
+				result.Steps = append(result.Steps, newStep(file.Path, op, err.Error()))
+			}
+			continue
+		}
+
+		change, exists, err := replayFile(file, path, dir, result)
+		if err != nil {
+			return result, err
+		}
+		if !exists || (change.Existed && change.Before == change.After) {
+			continue
+		}
+		result.Changes = append(result.Changes, *change)
+
+		if !dryRun {
+			if err := writeFile(filepath.Join(dir, filepath.FromSlash(path)), change.After); err != nil {
+				return result, err
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// replayFile applies the calls on a single file in memory, recording a step for each.
+// It reports whether the file exists after the calls.
+func replayFile(file *models.FileHistory, path, dir string, result *Result) (*FileChange, bool, error) {
+	change := &FileChange{Path: path}
+
+	content, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(path)))
+	switch {
+	case err == nil:
+		change.Existed = true
+		change.Before = string(content)
+	case !errors.Is(err, fs.ErrNotExist):
+		return nil, false, err
+	}
+
+	current, exists := change.Before, change.Existed
+	for _, op := range mutations(file) {
+		var conflict string
+		if op.Tool == constants.ToolNameWrite {
+			current, exists = op.Written, true
+		} else if edited, err := applyEdits(current, exists, op.Edits); err != nil {
+			conflict = err.Error()
+		} else {
+			current, exists = edited, true
+		}
+		result.Steps = append(result.Steps, newStep(path, op, conflict))
+	}
+
+	change.After = current
+	return change, exists, nil
+}
+
+// applyEdits applies the edits of an Edit or MultiEdit call. Like the live tool, either all
+// edits of a call apply or none do.
+func applyEdits(content string, exists bool, edits []models.FileEdit) (string, error) {
+	for i, edit := range edits {
+		var err error
+		content, exists, err = applyEdit(content, exists, edit)
+		if err != nil {
+			if len(edits) > 1 {
+				return "", fmt.Errorf("edit %d: %w", i+1, err)
+			}
+			return "", err
+		}
+	}
+	return content, nil
+}
+
+// applyEdit applies a single replacement with the checks the live Edit tool makes
+func applyEdit(content string, exists bool, edit models.FileEdit) (string, bool, error) {
+	if edit.OldString == "" {
+		// An empty old_string creates a new file
+		if exists && content != "" {
+			return "", exists, errors.New("file already exists")
+		}
+		return edit.NewString, true, nil
+	}
+	if !exists {
+		return "", exists, errors.New("file does not exist")
+	}
+
+	count := strings.Count(content, edit.OldString)
+	switch {
+	case count == 0:
+		return "", exists, errors.New("old_string not found")
+	case count > 1 && !edit.ReplaceAll:
+		return "", exists, fmt.Errorf("old_string found %d times but replace_all is not set", count)
+	case edit.ReplaceAll:
+		return strings.ReplaceAll(content, edit.OldString, edit.NewString), exists, nil
+	default:
+		return strings.Replace(content, edit.OldString, edit.NewString, 1), exists, nil
+	}
+}
+
+// mutations returns the successful calls that changed a file
+func mutations(file *models.FileHistory) []models.FileOperation {
+	var ops []models.FileOperation
+	for _, op := range file.Operations {
+		if op.Succeeded && op.Tool != constants.ToolNameRead {
+			ops = append(ops, op)
+		}
+	}
+	return ops
+}
+
+// newStep creates the step of a replayed call
+func newStep(path string, op models.FileOperation, conflict string) Step {
+	return Step{
+		Path:       path,
+		Tool:       op.Tool,
+		ToolCallID: op.ToolCallID,
+		Timestamp:  op.Timestamp,
+		Conflict:   conflict,
+	}
+}
+
+// writeFile writes replayed content, creating parent directories as needed
+func writeFile(path, content string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(content), 0644)
+}
@@ -0,0 +1,106 @@
+package replay
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/brads3290/cclogviewer/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// editOp returns a successful Edit of a single replacement
+func editOp(cwd, oldString, newString string) models.FileOperation {
+	return models.FileOperation{
+		Tool:      "Edit",
+		Succeeded: true,
+		CWD:       cwd,
+		Edits:     []models.FileEdit{{OldString: oldString, NewString: newString}},
+	}
+}
+
+func TestReplay(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte("a\nb\nb\n"), 0644))
+
+	files := []*models.FileHistory{
+		{
+			Path: filepath.Join(dir, "main.go"),
+			Operations: []models.FileOperation{
+				{Tool: "Read", Succeeded: true, CWD: dir},
+				editOp(dir, "a", "A"),
+				editOp(dir, "b", "B"),
+				{Tool: "Edit", Succeeded: false, CWD: dir, Edits: []models.FileEdit{{OldString: "zzz"}}},
+				{
+					Tool:      "MultiEdit",
+					Succeeded: true,
+					CWD:       dir,
+					Edits: []models.FileEdit{
+						{OldString: "b", NewString: "c", ReplaceAll: true},
+						{OldString: "c\nc", NewString: "C"},
+					},
+				},
+			},
+		},
+		{
+			Path: filepath.Join(dir, "pkg", "new.go"),
+			Operations: []models.FileOperation{
+				{Tool: "Write", Succeeded: true, CWD: dir, Written: "package pkg\n"},
+			},
+		},
+		{
+			Path:       filepath.Join(dir, "missing.go"),
+			Operations: []models.FileOperation{editOp(dir, "x", "y")},
+		},
+	}
+
+	result, err := Replay(files, dir, false)
+	require.NoError(t, err)
+
+	require.Len(t, result.Steps, 5)
+	assert.Equal(t, "", result.Steps[0].Conflict)
+	assert.Equal(t, "old_string found 2 times but replace_all is not set", result.Steps[1].Conflict)
+	assert.Equal(t, "", result.Steps[2].Conflict)
+	assert.Equal(t, "pkg/new.go", result.Steps[3].Path)
+	assert.Equal(t, "file does not exist", result.Steps[4].Conflict)
+	assert.Equal(t, 2, result.Conflicts())
+
+	content, err := os.ReadFile(filepath.Join(dir, "main.go"))
+	require.NoError(t, err)
+	assert.Equal(t, "A\nC\n", string(content))
+
+	content, err = os.ReadFile(filepath.Join(dir, "pkg", "new.go"))
+	require.NoError(t, err)
+	assert.Equal(t, "package pkg\n", string(content))
+
+	_, err = os.Stat(filepath.Join(dir, "missing.go"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestReplay_DryRun(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte("a\n"), 0644))
+
+	files := []*models.FileHistory{
+		{Path: filepath.Join(dir, "main.go"), Operations: []models.FileOperation{editOp(dir, "a", "b")}},
+	}
+
+	result, err := Replay(files, dir, true)
+	require.NoError(t, err)
+
+	require.Len(t, result.Changes, 1)
+	assert.Equal(t, FileChange{Path: "main.go", Existed: true, Before: "a\n", After: "b\n"}, result.Changes[0])
+
+	content, err := os.ReadFile(filepath.Join(dir, "main.go"))
+	require.NoError(t, err)
+	assert.Equal(t, "a\n", string(content))
+}
+
+func TestApplyEdits_MultiEditIsAtomic(t *testing.T) {
+	_, err := applyEdits("a b", true, []models.FileEdit{
+		{OldString: "a", NewString: "x"},
+		{OldString: "c", NewString: "y"},
+	})
+	assert.EqualError(t, err, "edit 2: old_string not found")
+}